@@ -1,23 +1,42 @@
+// Package cli parses the plugin command line options and environment variables into plugin.Config.
+// It is a helper for the plugin binaries: cli depends on plugin, plugin never imports cli.
 package cli
 
 import (
+	"os"
+	"path"
 	"strconv"
 
+	"github.com/netdata/go-orchestrator/pkg/multipath"
+	"github.com/netdata/go-orchestrator/plugin"
+
 	"github.com/jessevdk/go-flags"
 )
 
 // Option defines command line options.
+// Most of the options fall back to the NETDATA_PLUGIN_* environment variables, list values are ':' separated.
 type Option struct {
-	UpdateEvery int
-	Module      string   `short:"m" long:"modules" description:"module name to run" default:"all"`
-	ConfDir     []string `short:"c" long:"config-dir" description:"config dir to read"`
-	WatchPath   []string `short:"w" long:"watch-path" description:"config path to watch"`
-	Debug       bool     `short:"d" long:"debug" description:"debug mode"`
-	Version     bool     `short:"v" long:"version" description:"display the version and exit"`
+	UpdateEvery    int
+	Module         string   `short:"m" long:"modules" description:"modules to run: comma separated names and glob patterns, '!' excludes a module (e.g. 'nginx,redis', 'all,!mysql'), only the exact names run modules disabled by default or in the config" default:"all" env:"NETDATA_PLUGIN_MODULES"`
+	ConfDir        []string `short:"c" long:"config-dir" description:"config dir to read" env:"NETDATA_PLUGIN_CONFIG_DIR" env-delim:":"`
+	ModulesConfDir []string `long:"modules-config-dir" description:"modules config dir to read, default is the config dir" env:"NETDATA_PLUGIN_MODULES_CONFIG_DIR" env-delim:":"`
+	WatchPath      []string `short:"w" long:"watch-path" description:"config path to watch" env:"NETDATA_PLUGIN_WATCH_PATH" env-delim:":"`
+	StateFile      string   `long:"state-file" description:"jobs state file, the state is not saved if it is not set" env:"NETDATA_PLUGIN_STATE_FILE"`
+	LockDir        string   `long:"lock-dir" description:"jobs lock files dir, jobs are not locked if it is not set" env:"NETDATA_PLUGIN_LOCK_DIR"`
+	AdminSocket    string   `long:"admin-socket" description:"admin API unix socket path, the admin API is disabled if it is not set" env:"NETDATA_PLUGIN_ADMIN_SOCKET"`
+	LogLevel       string   `long:"log-level" description:"logging severity level" choice:"debug" choice:"info" choice:"warning" choice:"error" choice:"critical" default:"info" env:"NETDATA_PLUGIN_LOG_LEVEL"`
+	Debug          bool     `short:"d" long:"debug" description:"debug mode, the same as '--log-level=debug'"`
+	Version        bool     `short:"v" long:"version" description:"display the version and exit"`
+	CheckConfig    bool     `long:"check-config" description:"check the configuration files and exit"`
+	Once           bool     `long:"once" description:"run a single job of the module (-m), print collected values and exit"`
+	Job            string   `long:"job" description:"job name for the one-shot mode"`
+	Count          int      `long:"count" description:"number of data collections in the one-shot mode" default:"1"`
+	Format         string   `long:"format" description:"output format of the one-shot mode" choice:"table" choice:"json" default:"table"`
 }
 
-// Parse returns parsed command-line flags in Option struct
-func Parse(args []string) (*Option, error) {
+// Parse returns parsed command-line flags in Option struct and the plugin configuration ready to pass to plugin.New.
+// The name is the plugin name (see pluginConfig).
+func Parse(name string, args []string) (*Option, plugin.Config, error) {
 	opt := &Option{
 		UpdateEvery: 1,
 	}
@@ -27,14 +46,71 @@ func Parse(args []string) (*Option, error) {
 
 	rest, err := parser.ParseArgs(args)
 	if err != nil {
-		return nil, err
+		return nil, plugin.Config{}, err
 	}
 
 	if len(rest) > 1 {
 		if opt.UpdateEvery, err = strconv.Atoi(rest[1]); err != nil {
-			return nil, err
+			return nil, plugin.Config{}, err
 		}
 	}
+	if opt.Debug {
+		opt.LogLevel = "debug"
+	}
+
+	return opt, opt.pluginConfig(name), nil
+}
+
+// pluginConfig returns the plugin configuration.
+//
+// If the config dir is not set the netdata NETDATA_USER_CONFIG_DIR and NETDATA_STOCK_CONFIG_DIR
+// environment variables are used, or the netdata installation dirs relative to the working directory.
+// If the modules config dir is not set it is the config dir if that is set, otherwise
+// the '<name>' subdirectory of the netdata config dirs.
+func (o Option) pluginConfig(name string) plugin.Config {
+	return plugin.Config{
+		Name:              name,
+		ConfDir:           o.confDir(),
+		ModulesConfDir:    o.modulesConfDir(name),
+		ModulesSDConfPath: o.WatchPath,
+		StateFile:         o.StateFile,
+		LockDir:           o.LockDir,
+		RunModule:         o.Module,
+		MinUpdateEvery:    o.UpdateEvery,
+		LogLevel:          o.LogLevel,
+		AdminSocket:       o.AdminSocket,
+	}
+}
 
-	return opt, nil
+func (o Option) confDir() multipath.MultiPath {
+	if len(o.ConfDir) > 0 {
+		return o.ConfDir
+	}
+	return netdataConfDir("")
+}
+
+func (o Option) modulesConfDir(name string) multipath.MultiPath {
+	if len(o.ModulesConfDir) > 0 {
+		return o.ModulesConfDir
+	}
+	if len(o.ConfDir) > 0 {
+		return o.ConfDir
+	}
+	return netdataConfDir(name)
+}
+
+func netdataConfDir(subDir string) multipath.MultiPath {
+	userDir := os.Getenv("NETDATA_USER_CONFIG_DIR")
+	stockDir := os.Getenv("NETDATA_STOCK_CONFIG_DIR")
+	if userDir != "" && stockDir != "" {
+		return multipath.New(
+			path.Join(userDir, subDir),
+			path.Join(stockDir, subDir),
+		)
+	}
+	cd, _ := os.Getwd()
+	return multipath.New(
+		path.Join(cd, "/../../../../etc/netdata", subDir),
+		path.Join(cd, "/../../../../usr/lib/netdata/conf.d", subDir),
+	)
 }
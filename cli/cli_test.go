@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/plugin"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		env     map[string]string
+		wantCfg plugin.Config
+	}{
+		"flags": {
+			args: []string{"plugin", "-m", "nginx,!redis", "-c", "/etc/plugin", "--state-file", "/var/lib/state.json",
+				"--lock-dir", "/var/lock/plugin", "--log-level", "warning", "5"},
+			wantCfg: plugin.Config{
+				Name:           "test",
+				ConfDir:        []string{"/etc/plugin"},
+				ModulesConfDir: []string{"/etc/plugin"},
+				StateFile:      "/var/lib/state.json",
+				LockDir:        "/var/lock/plugin",
+				RunModule:      "nginx,!redis",
+				MinUpdateEvery: 5,
+				LogLevel:       "warning",
+			},
+		},
+		"environment variables": {
+			args: []string{"plugin", "-d"},
+			env: map[string]string{
+				"NETDATA_PLUGIN_MODULES":            "all,!mysql",
+				"NETDATA_PLUGIN_CONFIG_DIR":         "/etc/plugin:/usr/lib/plugin",
+				"NETDATA_PLUGIN_MODULES_CONFIG_DIR": "/etc/plugin/modules",
+				"NETDATA_PLUGIN_STATE_FILE":         "/var/lib/state.json",
+				"NETDATA_PLUGIN_ADMIN_SOCKET":       "/run/plugin.sock",
+			},
+			wantCfg: plugin.Config{
+				Name:           "test",
+				ConfDir:        []string{"/etc/plugin", "/usr/lib/plugin"},
+				ModulesConfDir: []string{"/etc/plugin/modules"},
+				StateFile:      "/var/lib/state.json",
+				RunModule:      "all,!mysql",
+				MinUpdateEvery: 1,
+				LogLevel:       "debug",
+				AdminSocket:    "/run/plugin.sock",
+			},
+		},
+		"netdata config dirs": {
+			args: []string{"plugin"},
+			env: map[string]string{
+				"NETDATA_USER_CONFIG_DIR":  "/etc/netdata",
+				"NETDATA_STOCK_CONFIG_DIR": "/usr/lib/netdata/conf.d",
+			},
+			wantCfg: plugin.Config{
+				Name:           "test",
+				ConfDir:        []string{"/etc/netdata", "/usr/lib/netdata/conf.d"},
+				ModulesConfDir: []string{"/etc/netdata/test", "/usr/lib/netdata/conf.d/test"},
+				RunModule:      "all",
+				MinUpdateEvery: 1,
+				LogLevel:       "info",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range test.env {
+				require.NoError(t, os.Setenv(k, v))
+			}
+			defer func() {
+				for k := range test.env {
+					_ = os.Unsetenv(k)
+				}
+			}()
+
+			opt, cfg, err := Parse("test", test.args)
+			require.NoError(t, err)
+
+			assert.NotNil(t, opt)
+			assert.Equal(t, test.wantCfg, cfg)
+		})
+	}
+}
@@ -4,12 +4,9 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
-	"path"
 
 	"github.com/netdata/go-orchestrator/cli"
 	"github.com/netdata/go-orchestrator/module"
-	"github.com/netdata/go-orchestrator/pkg/logger"
-	"github.com/netdata/go-orchestrator/pkg/multipath"
 	"github.com/netdata/go-orchestrator/plugin"
 
 	"github.com/jessevdk/go-flags"
@@ -45,51 +42,11 @@ func (e *example) Collect() map[string]int64 {
 	}
 }
 
-var (
-	cd, _    = os.Getwd()
-	name     = "goplugin"
-	userDir  = os.Getenv("NETDATA_USER_CONFIG_DIR")
-	stockDir = os.Getenv("NETDATA_STOCK_CONFIG_DIR")
-)
-
-func confDir(dirs []string) (mpath multipath.MultiPath) {
-	if len(dirs) > 0 {
-		return dirs
-	}
-	if userDir != "" && stockDir != "" {
-		return multipath.New(
-			userDir,
-			stockDir,
-		)
-	}
-	return multipath.New(
-		path.Join(cd, "/../../../../etc/netdata"),
-		path.Join(cd, "/../../../../usr/lib/netdata/conf.d"),
-	)
-}
-
-func modulesConfDir(dirs []string) multipath.MultiPath {
-	if len(dirs) > 0 {
-		return dirs
-	}
-	if userDir != "" && stockDir != "" {
-		return multipath.New(
-			path.Join(userDir, name),
-			path.Join(stockDir, name),
-		)
-	}
-	return multipath.New(
-		path.Join(cd, "/../../../../etc/netdata", name),
-		path.Join(cd, "/../../../../usr/lib/netdata/conf.d", name),
-	)
-}
+var name = "goplugin"
 
 func main() {
-	opt := parseCLI()
+	opt, cfg := parseCLI()
 
-	if opt.Debug {
-		logger.SetSeverity(logger.DEBUG)
-	}
 	if opt.Version {
 		fmt.Println(version)
 		os.Exit(0)
@@ -99,25 +56,39 @@ func main() {
 		Create: func() module.Module { return &example{} }},
 	)
 
-	p := plugin.New(plugin.Config{
-		Name:              name,
-		ConfDir:           confDir(opt.ConfDir),
-		ModulesConfDir:    modulesConfDir(opt.ConfDir),
-		ModulesSDConfPath: opt.WatchPath,
-		RunModule:         opt.Module,
-		MinUpdateEvery:    opt.UpdateEvery,
-	})
+	p := plugin.New(cfg)
+
+	if opt.CheckConfig {
+		if err := p.CheckConfig(os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if opt.Once {
+		err := p.RunOnce(os.Stdout, plugin.OnceConfig{
+			Module: opt.Module,
+			Job:    opt.Job,
+			Count:  opt.Count,
+			Format: opt.Format,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	p.Run()
 }
 
-func parseCLI() *cli.Option {
-	opt, err := cli.Parse(os.Args)
+func parseCLI() (*cli.Option, plugin.Config) {
+	opt, cfg, err := cli.Parse(name, os.Args)
 	if err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			os.Exit(0)
 		}
 		os.Exit(1)
 	}
-	return opt
+	return opt, cfg
 }
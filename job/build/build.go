@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -61,13 +62,35 @@ const (
 	duplicateGlobal   state = "duplicate_global"   // a job with the same FullName is registered by another plugin
 	registrationError state = "registration_error" // an error during registration (only 'too many open files')
 	buildError        state = "build_error"        // an error during building
+	stopped           state = "stopped"            // stopped on request (admin API)
 )
 
+// JobStatus is the build status of a job config.
+type JobStatus struct {
+	Config confgroup.Config
+	State  string
+}
+
+type jobCommand struct {
+	start    bool
+	fullName string
+	result   chan<- jobCommandResult
+}
+
+type jobCommandResult struct {
+	state state
+	err   error
+}
+
 type (
 	Manager struct {
 		PluginName string
 		Out        io.Writer
-		Modules    module.Registry
+		// OutLock serializes the jobs writes to Out, default is the module package level lock.
+		OutLock sync.Locker
+		Modules module.Registry
+		// Loggers creates the jobs loggers, default is the logger package default factory.
+		Loggers *logger.Factory
 		*logger.Logger
 
 		Runner    Runner
@@ -79,9 +102,14 @@ type (
 		startCache *startedCache
 		retryCache *retryCache
 
+		// mux guards Modules (they can be replaced during the configuration reload), grpCache and statuses.
+		mux      sync.Mutex
+		statuses map[cfgHash]JobStatus
+
 		addCh    chan []confgroup.Config
 		removeCh chan []confgroup.Config
 		retryCh  chan confgroup.Config
+		cmdCh    chan jobCommand
 	}
 )
 
@@ -98,10 +126,98 @@ func NewManager() *Manager {
 		addCh:      make(chan []confgroup.Config),
 		removeCh:   make(chan []confgroup.Config),
 		retryCh:    make(chan confgroup.Config),
+		cmdCh:      make(chan jobCommand),
+		statuses:   make(map[cfgHash]JobStatus),
 	}
 	return mgr
 }
 
+// SetModules replaces the registry of modules available for building jobs.
+// Already started jobs are not affected.
+func (m *Manager) SetModules(modules module.Registry) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.Modules = modules
+}
+
+// EnabledModules returns names of modules available for building jobs.
+func (m *Manager) EnabledModules() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	names := make([]string, 0, len(m.Modules))
+	for name := range m.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Groups returns the received configs per source.
+func (m *Manager) Groups() map[string][]confgroup.Config {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	groups := make(map[string][]confgroup.Config, len(m.grpCache.source))
+	for source, set := range m.grpCache.source {
+		for _, cfg := range set {
+			groups[source] = append(groups[source], cfg)
+		}
+		sort.Slice(groups[source], func(i, j int) bool {
+			return groups[source][i].FullName() < groups[source][j].FullName()
+		})
+	}
+	return groups
+}
+
+// Statuses returns build statuses of all received configs.
+func (m *Manager) Statuses() []JobStatus {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	statuses := make([]JobStatus, 0, len(m.statuses))
+	for _, st := range m.statuses {
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Config.FullName() != statuses[j].Config.FullName() {
+			return statuses[i].Config.FullName() < statuses[j].Config.FullName()
+		}
+		return statuses[i].Config.Source() < statuses[j].Config.Source()
+	})
+	return statuses
+}
+
+// StopJob stops the running job. The job stays stopped until it is started with StartJob
+// or its config is changed.
+func (m *Manager) StopJob(ctx context.Context, fullName string) error {
+	_, err := m.sendJobCommand(ctx, jobCommand{fullName: fullName})
+	return err
+}
+
+// StartJob starts the not running job (stopped or failed), it returns the job build state.
+func (m *Manager) StartJob(ctx context.Context, fullName string) (string, error) {
+	return m.sendJobCommand(ctx, jobCommand{fullName: fullName, start: true})
+}
+
+func (m *Manager) sendJobCommand(ctx context.Context, cmd jobCommand) (string, error) {
+	result := make(chan jobCommandResult, 1)
+	cmd.result = result
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case m.cmdCh <- cmd:
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-result:
+		return res.state, res.err
+	}
+}
+
 func (m *Manager) Run(ctx context.Context, in chan []*confgroup.Group) {
 	m.Info("instance is started")
 	defer func() { m.cleanup(); m.Info("instance is stopped") }()
@@ -118,10 +234,20 @@ func (m *Manager) Run(ctx context.Context, in chan []*confgroup.Group) {
 	<-ctx.Done()
 }
 
+// cleanup stops all started jobs in parallel and releases their registrations.
+// Jobs are stopped first, a registration must be held until the job is stopped.
 func (m *Manager) cleanup() {
 	for _, cancel := range *m.retryCache {
 		cancel()
 	}
+
+	var wg sync.WaitGroup
+	for name := range *m.startCache {
+		wg.Add(1)
+		go func(name string) { defer wg.Done(); m.Runner.Stop(name) }(name)
+	}
+	wg.Wait()
+
 	for name := range *m.startCache {
 		_ = m.Registry.Unregister(name)
 	}
@@ -149,7 +275,9 @@ func (m *Manager) processGroup(ctx context.Context, group *confgroup.Group) {
 	if group == nil {
 		return
 	}
+	m.mux.Lock()
 	added, removed := m.grpCache.put(group)
+	m.mux.Unlock()
 
 	select {
 	case <-ctx.Done():
@@ -175,6 +303,8 @@ func (m *Manager) runConfigProcessing(ctx context.Context) {
 			m.handleRemove(ctx, cfgs)
 		case cfg := <-m.retryCh:
 			m.handleAddCfg(ctx, cfg)
+		case cmd := <-m.cmdCh:
+			cmd.result <- m.handleJobCommand(ctx, cmd)
 		}
 	}
 }
@@ -204,7 +334,7 @@ func (m *Manager) handleRemove(ctx context.Context, cfgs []confgroup.Config) {
 func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 	if m.startCache.has(cfg) {
 		m.Infof("module '%s' job '%s' is being served by another job, skipping it", cfg.Module(), cfg.Name())
-		m.CurState.Save(cfg, duplicateLocal)
+		m.saveState(cfg, duplicateLocal)
 		return
 	}
 
@@ -217,7 +347,7 @@ func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 	job, err := m.buildJob(cfg)
 	if err != nil {
 		m.Warningf("couldn't build module '%s' job '%s': %v", cfg.Module(), cfg.Name(), err)
-		m.CurState.Save(cfg, buildError)
+		m.saveState(cfg, buildError)
 		return
 	}
 
@@ -238,33 +368,36 @@ func (m *Manager) handleAddCfg(ctx context.Context, cfg confgroup.Config) {
 	switch detection(job) {
 	case success:
 		if ok, err := m.Registry.Register(cfg.FullName()); ok || err != nil && !isTooManyOpenFiles(err) {
-			m.CurState.Save(cfg, success)
+			m.saveState(cfg, success)
 			m.Runner.Start(job)
 			m.startCache.put(cfg)
 		} else if isTooManyOpenFiles(err) {
 			m.Error(err)
-			m.CurState.Save(cfg, registrationError)
+			m.saveState(cfg, registrationError)
 		} else {
 			m.Infof("module '%s' job '%s'  is being served by another plugin, skipping it", cfg.Module(), cfg.Name())
-			m.CurState.Save(cfg, duplicateGlobal)
+			m.saveState(cfg, duplicateGlobal)
 		}
 	case retry:
 		m.Infof("module '%s' job '%s' detection failed, will retry in %d seconds", cfg.Module(), cfg.Name(),
 			cfg.AutoDetectionRetry())
-		m.CurState.Save(cfg, retry)
+		m.saveState(cfg, retry)
 		ctx, cancel := context.WithCancel(ctx)
 		m.retryCache.put(cfg, cancel)
 		go retryTask(ctx, m.retryCh, cfg)
 	case failed:
-		m.CurState.Save(cfg, failed)
+		m.saveState(cfg, failed)
 	default:
 		m.Warningf("module '%s' job '%s' detection: unknown state", cfg.Module(), cfg.Name())
 	}
 }
 
 func (m *Manager) handleRemoveCfg(cfg confgroup.Config) {
-	defer m.CurState.Remove(cfg)
+	defer m.removeState(cfg)
+	m.stopJob(cfg)
+}
 
+func (m *Manager) stopJob(cfg confgroup.Config) {
 	if m.startCache.has(cfg) {
 		m.Runner.Stop(cfg.FullName())
 		_ = m.Registry.Unregister(cfg.FullName())
@@ -277,8 +410,69 @@ func (m *Manager) handleRemoveCfg(cfg confgroup.Config) {
 	}
 }
 
+func (m *Manager) handleJobCommand(ctx context.Context, cmd jobCommand) jobCommandResult {
+	m.mux.Lock()
+	var cfg confgroup.Config
+	var cur state
+	for _, st := range m.statuses {
+		if st.Config.FullName() != cmd.fullName {
+			continue
+		}
+		// prefer the config the job is started (or being retried) with.
+		if cfg == nil || st.State == success || st.State == retry {
+			cfg, cur = st.Config, st.State
+		}
+	}
+	m.mux.Unlock()
+
+	if cfg == nil {
+		return jobCommandResult{err: fmt.Errorf("job '%s' not found", cmd.fullName)}
+	}
+
+	if !cmd.start {
+		if cur != success && cur != retry {
+			return jobCommandResult{state: cur, err: fmt.Errorf("job '%s' is not running (%s)", cmd.fullName, cur)}
+		}
+		m.Infof("module '%s' job '%s' is stopped on request", cfg.Module(), cfg.Name())
+		m.stopJob(cfg)
+		m.saveState(cfg, stopped)
+		return jobCommandResult{state: stopped}
+	}
+
+	if cur == success {
+		return jobCommandResult{state: cur, err: fmt.Errorf("job '%s' is already running", cmd.fullName)}
+	}
+	m.Infof("module '%s' job '%s' is started on request", cfg.Module(), cfg.Name())
+	m.handleAddCfg(ctx, cfg)
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return jobCommandResult{state: m.statuses[cfg.Hash()].State}
+}
+
+func (m *Manager) saveState(cfg confgroup.Config, st state) {
+	m.mux.Lock()
+	m.statuses[cfg.Hash()] = JobStatus{Config: cfg, State: st}
+	m.mux.Unlock()
+	m.CurState.Save(cfg, st)
+}
+
+func (m *Manager) removeState(cfg confgroup.Config) {
+	m.mux.Lock()
+	delete(m.statuses, cfg.Hash())
+	m.mux.Unlock()
+	m.CurState.Remove(cfg)
+}
+
+// BuildJob creates the job from the config, the job is neither registered nor started.
+func (m *Manager) BuildJob(cfg confgroup.Config) (*module.Job, error) {
+	return m.buildJob(cfg)
+}
+
 func (m *Manager) buildJob(cfg confgroup.Config) (*module.Job, error) {
+	m.mux.Lock()
 	creator, ok := m.Modules[cfg.Module()]
+	m.mux.Unlock()
 	if !ok {
 		return nil, fmt.Errorf("couldn't find '%s' module, job '%s'", cfg.Module(), cfg.Name())
 	}
@@ -296,8 +490,11 @@ func (m *Manager) buildJob(cfg confgroup.Config) (*module.Job, error) {
 		UpdateEvery:     cfg.UpdateEvery(),
 		AutoDetectEvery: cfg.AutoDetectionRetry(),
 		Priority:        cfg.Priority(),
+		Labels:          cfg.Labels(),
 		Module:          mod,
 		Out:             m.Out,
+		OutLock:         m.OutLock,
+		Loggers:         m.Loggers,
 	})
 	return job, nil
 }
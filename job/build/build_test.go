@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	jobpkg "github.com/netdata/go-orchestrator/job"
 	"github.com/netdata/go-orchestrator/job/confgroup"
 	"github.com/netdata/go-orchestrator/job/run"
 	"github.com/netdata/go-orchestrator/module"
@@ -78,6 +79,38 @@ func TestManager_Run(t *testing.T) {
 	assert.True(t, buf.String() != "")
 }
 
+func TestManager_cleanup(t *testing.T) {
+	var mux sync.Mutex
+	var events []string
+	record := func(event string) {
+		mux.Lock()
+		defer mux.Unlock()
+		events = append(events, event)
+	}
+
+	builder := NewManager()
+	builder.Runner = mockRunner{stop: func(name string) { record("stop " + name) }}
+	builder.Registry = mockRegistry{unregister: func(name string) { record("unregister " + name) }}
+	builder.startCache.put(confgroup.Config{"name": "job1", "module": "module"})
+	builder.startCache.put(confgroup.Config{"name": "job2", "module": "module"})
+
+	builder.cleanup()
+
+	assert.Len(t, events, 4)
+	assert.ElementsMatch(t, []string{"stop module_job1", "stop module_job2"}, events[:2])
+	assert.ElementsMatch(t, []string{"unregister module_job1", "unregister module_job2"}, events[2:])
+}
+
+type mockRunner struct{ stop func(name string) }
+
+func (m mockRunner) Start(_ jobpkg.Job)   {}
+func (m mockRunner) Stop(fullName string) { m.stop(fullName) }
+
+type mockRegistry struct{ unregister func(name string) }
+
+func (m mockRegistry) Register(_ string) (bool, error) { return true, nil }
+func (m mockRegistry) Unregister(name string) error    { m.unregister(name); return nil }
+
 func prepareMockRegistry() module.Registry {
 	reg := module.Registry{}
 	reg.Register("success", module.Creator{
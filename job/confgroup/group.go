@@ -1,8 +1,10 @@
 package confgroup
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/netdata/go-orchestrator/module"
 
@@ -27,6 +29,7 @@ func (c Config) FullName() string          { return fullName(c.Name(), c.Module(
 func (c Config) UpdateEvery() int          { v, _ := c.get("update_every").(int); return v }
 func (c Config) AutoDetectionRetry() int   { v, _ := c.get("autodetection_retry").(int); return v }
 func (c Config) Priority() int             { v, _ := c.get("priority").(int); return v }
+func (c Config) Labels() map[string]string { return cleanLabels(toStringMap(c.get("labels"))) }
 func (c Config) Hash() uint64              { return calcHash(c) }
 func (c Config) Source() string            { v, _ := c.get("__source__").(string); return v }
 func (c Config) Provider() string          { v, _ := c.get("__provider__").(string); return v }
@@ -53,6 +56,16 @@ func (c Config) Apply(def Default) {
 	if c.UpdateEvery() < def.MinUpdateEvery && def.MinUpdateEvery > 0 {
 		c.set("update_every", def.MinUpdateEvery)
 	}
+	if len(def.Labels) > 0 {
+		labels := make(map[string]interface{})
+		for k, v := range def.Labels {
+			labels[k] = v
+		}
+		for k, v := range c.Labels() {
+			labels[k] = v
+		}
+		c.set("labels", labels)
+	}
 	if c.Name() == "" {
 		c.set("name", c.Module())
 	} else {
@@ -60,6 +73,49 @@ func (c Config) Apply(def Default) {
 	}
 }
 
+func toStringMap(v interface{}) map[string]string {
+	var m map[string]string
+	switch v := v.(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		m = make(map[string]string, len(v))
+		for k, val := range v {
+			m[k] = fmt.Sprint(val)
+		}
+	case map[interface{}]interface{}:
+		m = make(map[string]string, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = fmt.Sprint(val)
+		}
+	}
+	return m
+}
+
+// cleanLabels makes the labels safe for the plugin protocol (the CLABEL values are single quoted):
+// not allowed key characters are replaced with '_', quotes and control characters in values are replaced with ' ',
+// labels with empty keys are dropped.
+func cleanLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	cleaned := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k = reLabelKeyNotAllowed.ReplaceAllString(strings.TrimSpace(k), "_"); k == "" {
+			continue
+		}
+		cleaned[k] = strings.Map(func(r rune) rune {
+			if r == '\'' || unicode.IsControl(r) {
+				return ' '
+			}
+			return r
+		}, v)
+	}
+	return cleaned
+}
+
+var reLabelKeyNotAllowed = regexp.MustCompile(`[^a-zA-Z0-9_./-]`)
+
 func cleanName(name string) string {
 	return reSpace.ReplaceAllString(name, "_")
 }
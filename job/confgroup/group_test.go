@@ -117,6 +117,30 @@ func TestConfig_Priority(t *testing.T) {
 	}
 }
 
+func TestConfig_Labels(t *testing.T) {
+	tests := map[string]struct {
+		cfg      Config
+		expected map[string]string
+	}{
+		"string map":          {cfg: Config{"labels": map[string]string{"env": "prod"}}, expected: map[string]string{"env": "prod"}},
+		"interface map":       {cfg: Config{"labels": map[interface{}]interface{}{"port": 80}}, expected: map[string]string{"port": "80"}},
+		"not set":             {cfg: Config{}},
+		"quote in key":        {cfg: Config{"labels": map[string]string{"e'nv": "prod"}}, expected: map[string]string{"e_nv": "prod"}},
+		"space in key":        {cfg: Config{"labels": map[string]string{"app name": "web"}}, expected: map[string]string{"app_name": "web"}},
+		"k8s key":             {cfg: Config{"labels": map[string]string{"app.kubernetes.io/name": "web"}}, expected: map[string]string{"app.kubernetes.io/name": "web"}},
+		"empty key":           {cfg: Config{"labels": map[string]string{" ": "web", "env": "prod"}}, expected: map[string]string{"env": "prod"}},
+		"quote in value":      {cfg: Config{"labels": map[string]string{"env": "pr'od"}}, expected: map[string]string{"env": "pr od"}},
+		"newline in value":    {cfg: Config{"labels": map[string]string{"env": "prod\nBEGIN"}}, expected: map[string]string{"env": "prod BEGIN"}},
+		"space in value kept": {cfg: Config{"labels": map[string]string{"env": "prod eu"}}, expected: map[string]string{"env": "prod eu"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.Labels())
+		})
+	}
+}
+
 func TestConfig_Hash(t *testing.T) {
 	tests := map[string]struct {
 		one, two Config
@@ -283,6 +307,24 @@ func TestConfig_Apply(t *testing.T) {
 				"priority":            module.Priority,
 			},
 		},
+		"merge labels (job labels take precedence)": {
+			def: Default{
+				Labels: map[string]string{"env": "prod", "dc": "eu"},
+			},
+			origCfg: Config{
+				"name":   "name",
+				"module": "module",
+				"labels": map[interface{}]interface{}{"env": "dev"},
+			},
+			expectedCfg: Config{
+				"name":                "name",
+				"module":              "module",
+				"update_every":        module.UpdateEvery,
+				"autodetection_retry": module.AutoDetectionRetry,
+				"priority":            module.Priority,
+				"labels":              map[string]interface{}{"env": "dev", "dc": "eu"},
+			},
+		},
 		"set name to module name if name not set": {
 			def: Default{},
 			origCfg: Config{
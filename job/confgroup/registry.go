@@ -3,10 +3,33 @@ package confgroup
 type Registry map[string]Default
 
 type Default struct {
-	MinUpdateEvery     int `yaml:"-"`
-	UpdateEvery        int `yaml:"update_every"`
-	AutoDetectionRetry int `yaml:"autodetection_retry"`
-	Priority           int `yaml:"priority"`
+	MinUpdateEvery     int               `yaml:"-"`
+	UpdateEvery        int               `yaml:"update_every"`
+	AutoDetectionRetry int               `yaml:"autodetection_retry"`
+	Priority           int               `yaml:"priority"`
+	Labels             map[string]string `yaml:"labels"`
+}
+
+// Merge returns the defaults with not set (non-positive) values taken from the lower precedence defaults.
+// Labels are merged, the defaults labels take precedence.
+// Zero means not set, so a higher precedence defaults can't set a value back to 0.
+func (d Default) Merge(lower Default) Default {
+	merged := Default{
+		MinUpdateEvery:     firstPositive(d.MinUpdateEvery, lower.MinUpdateEvery),
+		UpdateEvery:        firstPositive(d.UpdateEvery, lower.UpdateEvery),
+		AutoDetectionRetry: firstPositive(d.AutoDetectionRetry, lower.AutoDetectionRetry),
+		Priority:           firstPositive(d.Priority, lower.Priority),
+	}
+	if len(d.Labels) > 0 || len(lower.Labels) > 0 {
+		merged.Labels = make(map[string]string)
+		for k, v := range lower.Labels {
+			merged.Labels[k] = v
+		}
+		for k, v := range d.Labels {
+			merged.Labels[k] = v
+		}
+	}
+	return merged
 }
 
 func (r Registry) Register(name string, def Default) {
@@ -40,3 +40,31 @@ func TestRegistry_Lookup(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, expected, actual)
 }
+
+func TestDefault_Merge(t *testing.T) {
+	tests := map[string]struct {
+		def, lower Default
+		expected   Default
+	}{
+		"not set values from lower": {
+			def:      Default{UpdateEvery: 5},
+			lower:    Default{MinUpdateEvery: 1, UpdateEvery: 1, AutoDetectionRetry: 10, Priority: 100},
+			expected: Default{MinUpdateEvery: 1, UpdateEvery: 5, AutoDetectionRetry: 10, Priority: 100},
+		},
+		"merge labels": {
+			def:      Default{Labels: map[string]string{"env": "dev"}},
+			lower:    Default{Labels: map[string]string{"env": "prod", "dc": "eu"}},
+			expected: Default{Labels: map[string]string{"env": "dev", "dc": "eu"}},
+		},
+		"no labels": {
+			def:      Default{Priority: 1},
+			expected: Default{Priority: 1},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.def.Merge(test.lower))
+		})
+	}
+}
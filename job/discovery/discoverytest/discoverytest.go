@@ -0,0 +1,38 @@
+// Package discoverytest contains helpers for the discovery providers tests.
+package discoverytest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/module"
+)
+
+// ReceiveGroups returns the next groups sent to 'in', the test fails if nothing is sent in 5 seconds.
+func ReceiveGroups(t *testing.T, in <-chan []*confgroup.Group) []*confgroup.Group {
+	t.Helper()
+	select {
+	case groups := <-in:
+		return groups
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for groups")
+	}
+	return nil
+}
+
+// NewConfig returns a job config with the module defaults applied, the fields are added to it
+// (e.g. the '__source__' or the module specific options).
+func NewConfig(mod, name string, fields confgroup.Config) confgroup.Config {
+	cfg := confgroup.Config{
+		"module":              mod,
+		"name":                name,
+		"update_every":        module.UpdateEvery,
+		"autodetection_retry": module.AutoDetectionRetry,
+		"priority":            module.Priority,
+	}
+	for k, v := range fields {
+		cfg[k] = v
+	}
+	return cfg
+}
@@ -0,0 +1,142 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	apiContainer struct {
+		ID     string            `json:"Id"`
+		Names  []string          `json:"Names"`
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+		Ports  []struct {
+			IP          string `json:"IP"`
+			PrivatePort int    `json:"PrivatePort"`
+			PublicPort  int    `json:"PublicPort"`
+			Type        string `json:"Type"`
+		} `json:"Ports"`
+		NetworkSettings struct {
+			Networks map[string]struct {
+				IPAddress string `json:"IPAddress"`
+			} `json:"Networks"`
+		} `json:"NetworkSettings"`
+	}
+	apiEvent struct {
+		Type   string `json:"Type"`
+		Action string `json:"Action"`
+		Actor  struct {
+			ID string `json:"ID"`
+		} `json:"Actor"`
+	}
+)
+
+// apiClient is a minimal Docker Engine API client.
+type apiClient struct {
+	httpClient *http.Client
+	baseURL    string
+	timeout    time.Duration
+}
+
+func newAPIClient(address string, timeout time.Duration) (*apiClient, error) {
+	d := &net.Dialer{Timeout: timeout}
+	transport := &http.Transport{DialContext: d.DialContext}
+	baseURL := address
+
+	switch {
+	case strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		if _, err := url.Parse(address); err != nil {
+			return nil, fmt.Errorf("parse address '%s': %v", address, err)
+		}
+		baseURL = strings.TrimSuffix(address, "/")
+	case strings.HasPrefix(address, "unix://"), strings.HasPrefix(address, "/"):
+		socket := strings.TrimPrefix(address, "unix://")
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", socket)
+		}
+		// the host is ignored, the connection is made to the socket.
+		baseURL = "http://docker"
+	default:
+		return nil, fmt.Errorf("unsupported address '%s'", address)
+	}
+
+	// there is no client timeout, the events stream is read until it is closed.
+	client := &apiClient{
+		httpClient: &http.Client{Transport: transport},
+		baseURL:    baseURL,
+		timeout:    timeout,
+	}
+	return client, nil
+}
+
+// containers returns the running containers, filters are the Docker API list filters.
+func (c *apiClient) containers(ctx context.Context, filters map[string][]string) ([]apiContainer, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.get(ctx, "/containers/json", filters)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	var containers []apiContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("decode containers: %v", err)
+	}
+	return containers, nil
+}
+
+// events returns the containers events stream, it is closed when ctx is done.
+func (c *apiClient) events(ctx context.Context) (io.ReadCloser, error) {
+	filters := map[string][]string{
+		"type":  {"container"},
+		"event": {"start", "die", "destroy"},
+	}
+	resp, err := c.get(ctx, "/events", filters)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *apiClient) get(ctx context.Context, path string, filters map[string][]string) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(filters) > 0 {
+		bs, err := json.Marshal(filters)
+		if err != nil {
+			return nil, err
+		}
+		u += "?" + url.Values{"filters": {string(bs)}}.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("GET '%s': %v", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		closeBody(resp)
+		return nil, fmt.Errorf("GET '%s': returned HTTP status code %d", path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+)
+
+// DefaultAddress is the Docker Engine API default Unix socket.
+const DefaultAddress = "unix:///var/run/docker.sock"
+
+const provider = "docker"
+
+type Config struct {
+	discovery.ProviderConfig
+	// ComposeConfig creates job configs for the running containers, a container target is Target.
+	pipeline.ComposeConfig
+	// Address is the Docker Engine API address: a Unix socket path, 'unix://<path>' or 'http://<host>:<port>'.
+	// Default is DefaultAddress.
+	Address string
+	// Timeout is the API requests timeout, default is 2 seconds.
+	Timeout time.Duration
+}
+
+// Discovery discovers the running containers.
+// Every container is a target group, it is removed when the container stops.
+type Discovery struct {
+	*logger.Logger
+	client     *apiClient
+	retryEvery time.Duration
+	// sources are the sent groups sources by container ID.
+	sources map[string]string
+}
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	if cfg.Address == "" {
+		cfg.Address = DefaultAddress
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second * 2
+	}
+
+	client, err := newAPIClient(cfg.Address, cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("docker discovery initialization: %v", err)
+	}
+	d := &Discovery{
+		Logger:     cfg.Loggers.New("discovery", "docker"),
+		client:     client,
+		retryEvery: time.Second * 10,
+		sources:    make(map[string]string),
+	}
+	return d, nil
+}
+
+// NewProvider returns a discovery provider of docker discoveries.
+func NewProvider(cfg Config) discovery.Provider {
+	return discovery.NewTargetProvider(cfg.ComposeConfig, func(pcfg discovery.ProviderConfig) (pipeline.TargetDiscoverer, error) {
+		cfg := cfg
+		cfg.ProviderConfig = pcfg
+		return NewDiscovery(cfg)
+	})
+}
+
+func (d Discovery) String() string {
+	return "docker discovery"
+}
+
+// Run lists the running containers and watches the containers events.
+// If the API is not available or the events stream breaks it retries, the containers are re-listed.
+func (d *Discovery) Run(ctx context.Context, in chan<- []*pipeline.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	for {
+		if err := d.watch(ctx, in); err != nil {
+			d.Warningf("%v, retrying in %s", err, d.retryEvery)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.retryEvery):
+		}
+	}
+}
+
+func (d *Discovery) watch(ctx context.Context, in chan<- []*pipeline.TargetGroup) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the events stream is opened before listing the containers to not miss events in between.
+	events, err := d.client.events(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = events.Close() }()
+
+	containers, err := d.client.containers(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if !send(ctx, in, d.syncGroups(containers)) {
+		return nil
+	}
+
+	dec := json.NewDecoder(events)
+	for {
+		var e apiEvent
+		if err := dec.Decode(&e); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read events: %v", err)
+		}
+
+		groups, err := d.handleEvent(ctx, e)
+		if err != nil {
+			return err
+		}
+		if !send(ctx, in, groups) {
+			return nil
+		}
+	}
+}
+
+// syncGroups returns groups for the containers and empty groups for the sent containers that are gone.
+func (d *Discovery) syncGroups(containers []apiContainer) []*pipeline.TargetGroup {
+	var groups []*pipeline.TargetGroup
+	seen := make(map[string]bool)
+	for _, c := range containers {
+		seen[c.ID] = true
+		groups = append(groups, d.addGroup(c))
+	}
+	for id := range d.sources {
+		if !seen[id] {
+			groups = append(groups, d.removeGroup(id))
+		}
+	}
+	return groups
+}
+
+func (d *Discovery) handleEvent(ctx context.Context, e apiEvent) ([]*pipeline.TargetGroup, error) {
+	if e.Type != "" && e.Type != "container" {
+		return nil, nil
+	}
+	id := e.Actor.ID
+
+	switch e.Action {
+	case "start":
+		containers, err := d.client.containers(ctx, map[string][]string{"id": {id}})
+		if err != nil {
+			return nil, err
+		}
+		var groups []*pipeline.TargetGroup
+		for _, c := range containers {
+			groups = append(groups, d.addGroup(c))
+		}
+		return groups, nil
+	case "die", "destroy":
+		if _, ok := d.sources[id]; ok {
+			return []*pipeline.TargetGroup{d.removeGroup(id)}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *Discovery) addGroup(c apiContainer) *pipeline.TargetGroup {
+	source := fmt.Sprintf("%s:%s", provider, shortID(c.ID))
+	d.sources[c.ID] = source
+	return &pipeline.TargetGroup{Source: source, Targets: []interface{}{newTarget(c)}}
+}
+
+func (d *Discovery) removeGroup(id string) *pipeline.TargetGroup {
+	source := d.sources[id]
+	delete(d.sources, id)
+	return &pipeline.TargetGroup{Source: source}
+}
+
+func send(ctx context.Context, in chan<- []*pipeline.TargetGroup, groups []*pipeline.TargetGroup) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case in <- groups:
+		return true
+	}
+}
@@ -0,0 +1,289 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"default address": {
+			cfg: Config{},
+		},
+		"http address": {
+			cfg: Config{Address: "http://127.0.0.1:2375"},
+		},
+		"unsupported address": {
+			cfg:     Config{Address: "tcp://127.0.0.1:2375"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscovery(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"templates": {
+			cfg: Config{ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: nginx"}}}},
+		},
+		"templates not set": {
+			wantErr: true,
+		},
+		"unsupported address": {
+			cfg: Config{
+				Address:       "tcp://127.0.0.1:2375",
+				ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: nginx"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewProvider(test.cfg)(discovery.ProviderConfig{
+				Registry: confgroup.Registry{"nginx": confgroup.Default{}},
+			})
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscovery_Run(t *testing.T) {
+	engine, socket, cleanup := newFakeEngine(t)
+	defer cleanup()
+
+	engine.addContainer(newTestContainer("aaaaaaaaaaaaaaaa", "web", "nginx:1.19", "172.17.0.2", 80))
+	engine.addContainer(newTestContainer("bbbbbbbbbbbbbbbb", "cache", "redis:6", "172.17.0.3", 6379))
+
+	d, err := NewDiscovery(Config{Address: "unix://" + socket})
+	require.NoError(t, err)
+
+	in := make(chan []*pipeline.TargetGroup)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, in)
+
+	// running containers.
+	assert.Equal(t, []*pipeline.TargetGroup{
+		newTestTargetGroup("aaaaaaaaaaaaaaaa", "web", "nginx:1.19", "172.17.0.2", 80),
+		newTestTargetGroup("bbbbbbbbbbbbbbbb", "cache", "redis:6", "172.17.0.3", 6379),
+	}, receiveTargets(t, in))
+
+	// container is started.
+	engine.addContainer(newTestContainer("cccccccccccccccc", "proxy", "nginx:1.18", "172.17.0.4", 8080))
+	engine.sendEvent(apiEventJSON("start", "cccccccccccccccc"))
+	assert.Equal(t, []*pipeline.TargetGroup{
+		newTestTargetGroup("cccccccccccccccc", "proxy", "nginx:1.18", "172.17.0.4", 8080),
+	}, receiveTargets(t, in))
+
+	// container is stopped.
+	engine.removeContainer("aaaaaaaaaaaaaaaa")
+	engine.sendEvent(apiEventJSON("die", "aaaaaaaaaaaaaaaa"))
+	assert.Equal(t, []*pipeline.TargetGroup{{Source: "docker:aaaaaaaaaaaa"}}, receiveTargets(t, in))
+}
+
+func TestNewProvider_Run(t *testing.T) {
+	engine, socket, cleanup := newFakeEngine(t)
+	defer cleanup()
+
+	engine.addContainer(newTestContainer("aaaaaaaaaaaaaaaa", "web", "nginx:1.19", "172.17.0.2", 80))
+	engine.addContainer(newTestContainer("bbbbbbbbbbbbbbbb", "cache", "redis:6", "172.17.0.3", 6379))
+
+	d, err := NewProvider(Config{
+		Address: "unix://" + socket,
+		ComposeConfig: pipeline.ComposeConfig{
+			Templates: []tmpl.Template{
+				{
+					Selector: `{{ glob .Image "nginx*" }}`,
+					Config:   "{{range .Ports}}- module: nginx\n  name: {{$.Name}}_{{.PrivatePort}}\n  url: {{ quote (printf \"http://%s:%d\" $.Address .PrivatePort) }}\n{{end}}",
+				},
+			},
+		},
+	})(discovery.ProviderConfig{Registry: confgroup.Registry{"nginx": confgroup.Default{}}})
+	require.NoError(t, err)
+
+	in := make(chan []*confgroup.Group)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, in)
+
+	assert.Equal(t, []*confgroup.Group{
+		{Source: "docker:aaaaaaaaaaaa", Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "web_80", confgroup.Config{"url": "http://172.17.0.2:80", "__source__": "docker:aaaaaaaaaaaa"})}},
+		{Source: "docker:bbbbbbbbbbbb"},
+	}, discoverytest.ReceiveGroups(t, in))
+}
+
+func TestTarget_Address(t *testing.T) {
+	target := Target{Networks: map[string]string{"none": "", "bridge": "172.17.0.2", "custom": "172.18.0.2"}}
+
+	assert.Equal(t, "172.17.0.2", target.Address())
+	assert.Equal(t, "", Target{}.Address())
+}
+
+func receiveTargets(t *testing.T, in chan []*pipeline.TargetGroup) []*pipeline.TargetGroup {
+	t.Helper()
+	select {
+	case groups := <-in:
+		return groups
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for groups")
+	}
+	return nil
+}
+
+func newTestTargetGroup(id, name, image, ip string, port int) *pipeline.TargetGroup {
+	return &pipeline.TargetGroup{
+		Source: "docker:" + id[:12],
+		Targets: []interface{}{
+			Target{
+				ID:       id,
+				Name:     name,
+				Image:    image,
+				Labels:   map[string]string{},
+				Networks: map[string]string{"bridge": ip},
+				Ports:    []Port{{PrivatePort: port, Type: "tcp"}},
+			},
+		},
+	}
+}
+
+func newTestContainer(id, name, image, ip string, port int) apiContainer {
+	var c apiContainer
+	c.ID = id
+	c.Names = []string{"/" + name}
+	c.Image = image
+	c.NetworkSettings.Networks = map[string]struct {
+		IPAddress string `json:"IPAddress"`
+	}{"bridge": {IPAddress: ip}}
+	c.Ports = append(c.Ports, struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	}{PrivatePort: port, Type: "tcp"})
+	return c
+}
+
+func apiEventJSON(action, id string) string {
+	return `{"Type":"container","Action":"` + action + `","Actor":{"ID":"` + id + `"}}`
+}
+
+// fakeEngine is a fake Docker Engine API.
+type fakeEngine struct {
+	mux        sync.Mutex
+	containers []apiContainer
+	events     chan string
+}
+
+func newFakeEngine(t *testing.T) (*fakeEngine, string, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-discovery-docker")
+	require.NoError(t, err)
+
+	socket := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	engine := &fakeEngine{events: make(chan string)}
+	srv := httptest.NewUnstartedServer(engine)
+	_ = srv.Listener.Close()
+	srv.Listener = ln
+	srv.Start()
+
+	return engine, socket, func() { srv.Close(); _ = os.RemoveAll(dir) }
+}
+
+func (e *fakeEngine) addContainer(c apiContainer) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.containers = append(e.containers, c)
+}
+
+func (e *fakeEngine) removeContainer(id string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	for i, c := range e.containers {
+		if c.ID == id {
+			e.containers = append(e.containers[:i], e.containers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *fakeEngine) sendEvent(event string) {
+	e.events <- event
+}
+
+func (e *fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/containers/json":
+		var filters map[string][]string
+		if v := r.URL.Query().Get("filters"); v != "" {
+			_ = json.Unmarshal([]byte(v), &filters)
+		}
+		e.mux.Lock()
+		containers := []apiContainer{}
+		for _, c := range e.containers {
+			if ids := filters["id"]; len(ids) == 0 || ids[0] == c.ID {
+				containers = append(containers, c)
+			}
+		}
+		e.mux.Unlock()
+		_ = json.NewEncoder(w).Encode(containers)
+	case "/events":
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-e.events:
+				_, _ = w.Write([]byte(event + "\n"))
+				w.(http.Flusher).Flush()
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
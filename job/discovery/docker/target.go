@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// Target is a running container, it is the templates data.
+	Target struct {
+		ID     string
+		Name   string
+		Image  string
+		Labels map[string]string
+		// Networks are the container IP addresses by network name.
+		Networks map[string]string
+		Ports    []Port
+	}
+	// Port is an exposed container port.
+	Port struct {
+		IP          string
+		PrivatePort int
+		PublicPort  int
+		// Type is the port protocol: 'tcp', 'udp' or 'sctp'.
+		Type string
+	}
+)
+
+// Address returns the container IP address in the first (by name) network it is connected to.
+func (t Target) Address() string {
+	names := make([]string, 0, len(t.Networks))
+	for name, ip := range t.Networks {
+		if ip != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return t.Networks[names[0]]
+}
+
+func newTarget(c apiContainer) Target {
+	t := Target{
+		ID:       c.ID,
+		Image:    c.Image,
+		Labels:   c.Labels,
+		Networks: make(map[string]string),
+	}
+	if len(c.Names) > 0 {
+		t.Name = strings.TrimPrefix(c.Names[0], "/")
+	}
+	if t.Labels == nil {
+		t.Labels = make(map[string]string)
+	}
+	for name, network := range c.NetworkSettings.Networks {
+		t.Networks[name] = network.IPAddress
+	}
+	for _, p := range c.Ports {
+		t.Ports = append(t.Ports, Port{IP: p.IP, PrivatePort: p.PrivatePort, PublicPort: p.PublicPort, Type: p.Type})
+	}
+	return t
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
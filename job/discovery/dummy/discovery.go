@@ -12,6 +12,7 @@ import (
 type Config struct {
 	Registry confgroup.Registry
 	Names    []string
+	Loggers  *logger.Factory
 }
 
 func validateConfig(cfg Config) error {
@@ -37,7 +38,7 @@ func NewDiscovery(cfg Config) (*Discovery, error) {
 	d := &Discovery{
 		reg:    cfg.Registry,
 		names:  cfg.Names,
-		Logger: logger.New("discovery", "dummy"),
+		Logger: cfg.Loggers.New("discovery", "dummy"),
 	}
 	return d, nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/netdata/go-orchestrator/job/confgroup"
 	"github.com/netdata/go-orchestrator/pkg/logger"
@@ -12,8 +13,18 @@ import (
 
 type Config struct {
 	Registry confgroup.Registry
-	Read     []string
-	Watch    []string
+	// Read and Watch are the files patterns, in addition to the filepath.Match syntax
+	// the '**' path element matches zero or more directories. See Match.
+	Read  []string
+	Watch []string
+	// Exclude are the files and dirs exclusion patterns. See IsExcluded.
+	Exclude []string
+	// Polling forces the watcher polling mode. By default the watcher uses inotify
+	// and falls back to polling if inotify is not available.
+	Polling bool
+	// PollEvery is the watcher polling interval, default is 10 seconds.
+	PollEvery time.Duration
+	Loggers   *logger.Factory
 }
 
 func validateConfig(cfg Config) error {
@@ -42,7 +53,7 @@ func NewDiscovery(cfg Config) (*Discovery, error) {
 	}
 
 	d := Discovery{
-		Logger: logger.New("discovery", "file manager"),
+		Logger: cfg.Loggers.New("discovery", "file manager"),
 	}
 	if err := d.registerDiscoverers(cfg); err != nil {
 		return nil, fmt.Errorf("file discovery initialization: %v", err)
@@ -56,10 +67,20 @@ func (d Discovery) String() string {
 
 func (d *Discovery) registerDiscoverers(cfg Config) error {
 	if len(cfg.Read) != 0 {
-		d.discoverers = append(d.discoverers, NewReader(cfg.Registry, cfg.Read))
+		r := NewReader(cfg.Registry, cfg.Read)
+		r.Logger = cfg.Loggers.New("discovery", "file reader")
+		r.excludes = cfg.Exclude
+		d.discoverers = append(d.discoverers, r)
 	}
 	if len(cfg.Watch) != 0 {
-		d.discoverers = append(d.discoverers, NewWatcher(cfg.Registry, cfg.Watch))
+		w := NewWatcher(cfg.Registry, cfg.Watch)
+		w.Logger = cfg.Loggers.New("discovery", "file watcher")
+		w.excludes = cfg.Exclude
+		w.forcePolling = cfg.Polling
+		if cfg.PollEvery > 0 {
+			w.pollEvery = cfg.PollEvery
+		}
+		d.discoverers = append(d.discoverers, w)
 	}
 	if len(d.discoverers) == 0 {
 		return errors.New("zero registered discoverers")
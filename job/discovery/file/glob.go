@@ -0,0 +1,139 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const sep = string(filepath.Separator)
+
+// Match reports whether the name matches the shell pattern. In addition to the filepath.Match syntax
+// the '**' path element matches zero or more path elements, e.g. '/etc/sd/**/*.conf'.
+func Match(pattern, name string) (bool, error) {
+	if !isRecursive(pattern) {
+		return filepath.Match(pattern, name)
+	}
+	return matchElems(splitPath(pattern), splitPath(name))
+}
+
+// Glob returns the names of all files matching the pattern or nil if there is no matching file,
+// the pattern syntax is the same as in Match.
+func Glob(pattern string) ([]string, error) {
+	if !isRecursive(pattern) {
+		return filepath.Glob(pattern)
+	}
+	for _, elem := range splitPath(pattern) {
+		if _, err := filepath.Match(elem, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	_ = filepath.Walk(staticBase(pattern), func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			// unreadable dirs are skipped.
+			return nil
+		}
+		if ok, _ := Match(pattern, path); ok {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, nil
+}
+
+// IsExcluded reports whether the path matches any of the exclusion patterns. A pattern without a path separator
+// is matched against the file name and the dir names of the path, e.g. '*.bak' or '.git'.
+func IsExcluded(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, sep) {
+			if ok, _ := Match(pattern, path); ok {
+				return true
+			}
+			continue
+		}
+		for _, name := range splitPath(path) {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchElems(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true, nil
+			}
+			for i := 0; i <= len(name); i++ {
+				if ok, err := matchElems(pattern, name[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		if ok, err := filepath.Match(pattern[0], name[0]); !ok || err != nil {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// staticBase returns the pattern leading path elements without the meta characters.
+func staticBase(pattern string) string {
+	elems := strings.Split(pattern, sep)
+	var i int
+	for i < len(elems) && !hasMeta(elems[i]) {
+		i++
+	}
+	base := strings.Join(elems[:i], sep)
+	switch {
+	case base != "":
+		return base
+	case filepath.IsAbs(pattern):
+		return sep
+	default:
+		return "."
+	}
+}
+
+// walkDirs returns the dir and all its not excluded subdirectories.
+func walkDirs(dir string, excludes []string) []string {
+	var dirs []string
+	_ = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.IsDir() {
+			return nil
+		}
+		if path != dir && IsExcluded(excludes, path) {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs
+}
+
+func isRecursive(pattern string) bool {
+	for _, elem := range strings.Split(pattern, sep) {
+		if elem == "**" {
+			return true
+		}
+	}
+	return false
+}
+
+func splitPath(path string) []string {
+	return strings.Split(filepath.Clean(path), sep)
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
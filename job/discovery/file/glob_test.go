@@ -0,0 +1,119 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		name    string
+		matches bool
+		wantErr bool
+	}{
+		"no '**', match":              {pattern: "/sd/*.conf", name: "/sd/a.conf", matches: true},
+		"no '**', subdir":             {pattern: "/sd/*.conf", name: "/sd/x/a.conf"},
+		"'**' matches zero dirs":      {pattern: "/sd/**/*.conf", name: "/sd/a.conf", matches: true},
+		"'**' matches one dir":        {pattern: "/sd/**/*.conf", name: "/sd/x/a.conf", matches: true},
+		"'**' matches several dirs":   {pattern: "/sd/**/*.conf", name: "/sd/x/y/z/a.conf", matches: true},
+		"'**' in the middle":          {pattern: "/sd/**/x/*.conf", name: "/sd/y/x/a.conf", matches: true},
+		"'**' in the middle, no dir":  {pattern: "/sd/**/x/*.conf", name: "/sd/y/a.conf"},
+		"'**' at the end":             {pattern: "/sd/**", name: "/sd/x/a.conf", matches: true},
+		"'**', file name mismatch":    {pattern: "/sd/**/*.conf", name: "/sd/x/a.yml"},
+		"'**', base mismatch":         {pattern: "/sd/**/*.conf", name: "/etc/x/a.conf"},
+		"'**' not a path element":     {pattern: "/sd/a**/*.conf", name: "/sd/x/y/a.conf"},
+		"'**', bad pattern":           {pattern: "/sd/**/[.conf", name: "/sd/x/a.conf", wantErr: true},
+		"'**', relative path matches": {pattern: "sd/**/*.conf", name: "sd/x/a.conf", matches: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ok, err := Match(test.pattern, test.name)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.matches, ok)
+			}
+		})
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tmp := newTmpDir(t, "glob-*")
+	defer tmp.cleanup()
+
+	tmp.mkdir("x")
+	tmp.mkdir("x/y")
+	for _, name := range []string{"a.conf", "b.yml", "x/a.conf", "x/y/a.conf", "x/y/b.yml"} {
+		tmp.writeString(tmp.join(name), "")
+	}
+
+	tests := map[string]struct {
+		pattern  string
+		expected []string
+		wantErr  bool
+	}{
+		"no '**'": {
+			pattern:  tmp.join("*.conf"),
+			expected: []string{tmp.join("a.conf")},
+		},
+		"'**'": {
+			pattern:  tmp.join("**/*.conf"),
+			expected: []string{tmp.join("a.conf"), tmp.join("x/a.conf"), tmp.join("x/y/a.conf")},
+		},
+		"'**' in the middle": {
+			pattern:  tmp.join("**/y/*.yml"),
+			expected: []string{tmp.join("x/y/b.yml")},
+		},
+		"'**', no matches": {
+			pattern: tmp.join("**/*.json"),
+		},
+		"'**', base not exists": {
+			pattern: tmp.join("not_exists/**/*.conf"),
+		},
+		"'**', bad pattern": {
+			pattern: tmp.join("**/[.conf"),
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matches, err := Glob(test.pattern)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.ElementsMatch(t, test.expected, matches)
+			}
+		})
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	tests := map[string]struct {
+		patterns []string
+		path     string
+		excluded bool
+	}{
+		"no patterns":                {path: "/sd/a.conf"},
+		"file name":                  {patterns: []string{"*.bak"}, path: "/sd/a.conf.bak", excluded: true},
+		"file name mismatch":         {patterns: []string{"*.bak"}, path: "/sd/a.conf"},
+		"dir name":                   {patterns: []string{"old"}, path: "/sd/old/a.conf", excluded: true},
+		"dir name is the path":       {patterns: []string{"old"}, path: "/sd/old", excluded: true},
+		"path pattern":               {patterns: []string{"/sd/x/*.conf"}, path: "/sd/x/a.conf", excluded: true},
+		"path pattern mismatch":      {patterns: []string{"/sd/x/*.conf"}, path: "/sd/y/a.conf"},
+		"path pattern with '**'":     {patterns: []string{"/sd/**/tmp/*"}, path: "/sd/x/tmp/a.conf", excluded: true},
+		"the second pattern matches": {patterns: []string{"*.bak", "*.swp"}, path: "/sd/.a.conf.swp", excluded: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) { assert.Equal(t, test.excluded, IsExcluded(test.patterns, test.path)) })
+	}
+}
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -53,7 +54,7 @@ func parseStaticFormat(reg confgroup.Registry, path string, bs []byte) (*confgro
 	}
 	for _, cfg := range modCfg.Jobs {
 		cfg.SetModule(name)
-		def := mergeDef(modCfg.Default, modDef)
+		def := modCfg.Default.Merge(modDef)
 		cfg.Apply(def)
 	}
 	group := &confgroup.Group{
@@ -85,6 +86,19 @@ func parseSDFormat(reg confgroup.Registry, path string, bs []byte) (*confgroup.G
 	return group, nil
 }
 
+// ParseSD parses the SD format (a YAML or JSON list of job configs) data, the source is the group source.
+// Configs of modules not in the registry are skipped, empty data is an empty group.
+func ParseSD(reg confgroup.Registry, source string, bs []byte) (*confgroup.Group, error) {
+	switch cfgFormat(bs) {
+	case sdFormat:
+		return parseSDFormat(reg, source, bs)
+	case unknownEmptyFormat:
+		return &confgroup.Group{Source: source}, nil
+	default:
+		return nil, errors.New("not the SD format")
+	}
+}
+
 func cfgFormat(bs []byte) format {
 	var data interface{}
 	if err := yaml.Unmarshal(bs, &data); err != nil {
@@ -108,24 +122,41 @@ func cfgFormat(bs []byte) format {
 	}
 }
 
-func mergeDef(a, b confgroup.Default) confgroup.Default {
-	return confgroup.Default{
-		MinUpdateEvery:     firstPositive(a.MinUpdateEvery, b.MinUpdateEvery),
-		UpdateEvery:        firstPositive(a.UpdateEvery, b.UpdateEvery),
-		AutoDetectionRetry: firstPositive(a.AutoDetectionRetry, b.AutoDetectionRetry),
-		Priority:           firstPositive(a.Priority, b.Priority),
-	}
-}
-
-func firstPositive(value int, others ...int) int {
-	if value > 0 || len(others) == 0 {
-		return value
-	}
-	return firstPositive(others[0], others[1:]...)
-}
-
 func fileName(path string) string {
 	_, file := filepath.Split(path)
 	ext := filepath.Ext(path)
 	return file[:len(file)-len(ext)]
 }
+
+// Check parses the file the way the discovery does. In addition to the parsed group it returns modules
+// of the configs that are dropped because the module is not in the registry (an empty string for a config
+// without the module). It is intended to be used for the configuration check, not for the discovery.
+func Check(reg confgroup.Registry, path string) (group *confgroup.Group, unknown []string, err error) {
+	group, err = parse(reg, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch cfgFormat(bs) {
+	case staticFormat:
+		if _, ok := reg.Lookup(fileName(path)); !ok {
+			unknown = append(unknown, fileName(path))
+		}
+	case sdFormat:
+		var cfgs sdConfig
+		if err := yaml.Unmarshal(bs, &cfgs); err != nil {
+			return nil, nil, err
+		}
+		for _, cfg := range cfgs {
+			if _, ok := reg.Lookup(cfg.Module()); !ok || cfg.Module() == "" {
+				unknown = append(unknown, cfg.Module())
+			}
+		}
+	}
+	return group, unknown, nil
+}
@@ -397,3 +397,118 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestCheck(t *testing.T) {
+	reg := confgroup.Registry{"module": {}}
+
+	tests := map[string]struct {
+		filename    string
+		content     string
+		wantConfigs int
+		wantUnknown []string
+		wantErr     bool
+	}{
+		"static, known module": {
+			filename:    "module.conf",
+			content:     "jobs:\n  - name: job1\n  - name: job2\n",
+			wantConfigs: 2,
+		},
+		"static, unknown module": {
+			filename:    "unknown.conf",
+			content:     "jobs:\n  - name: job1\n",
+			wantUnknown: []string{"unknown"},
+		},
+		"sd, known and unknown modules": {
+			filename:    "sd.conf",
+			content:     "- module: module\n- module: unknown\n- name: job\n",
+			wantConfigs: 1,
+			wantUnknown: []string{"unknown", ""},
+		},
+		"empty": {
+			filename: "empty.conf",
+		},
+		"unknown format": {
+			filename: "invalid.conf",
+			content:  "unknown",
+			wantErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmp := newTmpDir(t, "check-file-*")
+			defer tmp.cleanup()
+			filename := tmp.join(test.filename)
+			tmp.writeString(filename, test.content)
+
+			group, unknown, err := Check(reg, filename)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if test.wantConfigs > 0 {
+				require.NotNil(t, group)
+				assert.Len(t, group.Configs, test.wantConfigs)
+			}
+			assert.Equal(t, test.wantUnknown, unknown)
+		})
+	}
+}
+
+func TestParseSD(t *testing.T) {
+	reg := confgroup.Registry{"module1": {}}
+	tests := map[string]struct {
+		data      string
+		wantGroup *confgroup.Group
+		wantErr   bool
+	}{
+		"yaml": {
+			data: "- module: module1\n  name: job1\n- module: module2\n  name: job2\n",
+			wantGroup: &confgroup.Group{
+				Source: "source",
+				Configs: []confgroup.Config{
+					{
+						"module":              "module1",
+						"name":                "job1",
+						"update_every":        module.UpdateEvery,
+						"autodetection_retry": module.AutoDetectionRetry,
+						"priority":            module.Priority,
+					},
+				},
+			},
+		},
+		"json": {
+			data: `[{"module": "module1", "name": "job1"}]`,
+			wantGroup: &confgroup.Group{
+				Source: "source",
+				Configs: []confgroup.Config{
+					{
+						"module":              "module1",
+						"name":                "job1",
+						"update_every":        module.UpdateEvery,
+						"autodetection_retry": module.AutoDetectionRetry,
+						"priority":            module.Priority,
+					},
+				},
+			},
+		},
+		"empty":         {data: "", wantGroup: &confgroup.Group{Source: "source"}},
+		"static format": {data: "jobs:\n  - name: job1\n", wantErr: true},
+		"invalid":       {data: "- module: [module1", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			group, err := ParseSD(reg, "source", []byte(test.data))
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantGroup, group)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package file
 import (
 	"context"
 	"os"
-	"path/filepath"
 
 	"github.com/netdata/go-orchestrator/job/confgroup"
 	"github.com/netdata/go-orchestrator/pkg/logger"
@@ -20,6 +19,8 @@ type (
 type Reader struct {
 	reg   confgroup.Registry
 	paths []string
+	// excludes are the exclusion patterns, see IsExcluded.
+	excludes []string
 	*logger.Logger
 }
 
@@ -48,12 +49,15 @@ func (r Reader) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 
 func (r Reader) groups() (groups []*confgroup.Group) {
 	for _, pattern := range r.paths {
-		matches, err := filepath.Glob(pattern)
+		matches, err := Glob(pattern)
 		if err != nil {
 			continue
 		}
 
 		for _, path := range matches {
+			if IsExcluded(r.excludes, path) {
+				continue
+			}
 			if fi, err := os.Stat(path); err != nil || !fi.Mode().IsRegular() {
 				continue
 			}
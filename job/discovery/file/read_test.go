@@ -95,3 +95,52 @@ func TestReader_Run(t *testing.T) {
 
 	sim.run(t)
 }
+
+func TestReader_Run_RecursiveAndExcluded(t *testing.T) {
+	tmp := newTmpDir(t, "reader-run-recursive-*")
+	defer tmp.cleanup()
+
+	tmp.mkdir("x")
+	tmp.mkdir("x/old")
+	module1 := tmp.join("x/module1.conf")
+	excluded1 := tmp.join("x/module1.conf.bak")
+	excluded2 := tmp.join("x/old/module1.conf")
+
+	for _, filename := range []string{module1, excluded1, excluded2} {
+		tmp.writeYAML(filename, staticConfig{
+			Jobs: []confgroup.Config{{"name": "name"}},
+		})
+	}
+
+	reg := confgroup.Registry{
+		"module1": {},
+	}
+	discovery := prepareDiscovery(t, Config{
+		Registry: reg,
+		Read:     []string{tmp.join("**/module1.conf*")},
+		Exclude:  []string{"*.bak", "old"},
+	})
+	expected := []*confgroup.Group{
+		{
+			Source: module1,
+			Configs: []confgroup.Config{
+				{
+					"name":                "name",
+					"module":              "module1",
+					"update_every":        module.UpdateEvery,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+					"__source__":          module1,
+					"__provider__":        "file reader",
+				},
+			},
+		},
+	}
+
+	sim := discoverySim{
+		discovery:      discovery,
+		expectedGroups: expected,
+	}
+
+	sim.run(t)
+}
@@ -109,6 +109,21 @@ func (d *tmpDir) renameFile(origFilename, newFilename string) {
 	require.NoError(d.t, err)
 }
 
+func (d *tmpDir) mkdir(dirname string) {
+	err := os.Mkdir(d.join(dirname), 0755)
+	require.NoError(d.t, err)
+}
+
+func (d *tmpDir) removeDir(dirname string) {
+	err := os.RemoveAll(d.join(dirname))
+	require.NoError(d.t, err)
+}
+
+func (d *tmpDir) symlink(target, linkname string) {
+	err := os.Symlink(target, linkname)
+	require.NoError(d.t, err)
+}
+
 func (d *tmpDir) writeYAML(filename string, in interface{}) {
 	bs, err := yaml.Marshal(in)
 	require.NoError(d.t, err)
@@ -121,6 +136,20 @@ func (d *tmpDir) writeString(filename, data string) {
 	require.NoError(d.t, err)
 }
 
+// replaceYAML writes the file atomically (a temporary file is renamed), the watcher can't read it half-written.
+func (d *tmpDir) replaceYAML(filename string, in interface{}) {
+	bs, err := yaml.Marshal(in)
+	require.NoError(d.t, err)
+	d.replaceString(filename, string(bs))
+}
+
+// replaceString writes the file atomically, see replaceYAML.
+func (d *tmpDir) replaceString(filename, data string) {
+	tmp := filename + ".tmp"
+	d.writeString(tmp, data)
+	d.renameFile(tmp, filename)
+}
+
 func sortGroups(groups []*confgroup.Group) {
 	if len(groups) == 0 {
 		return
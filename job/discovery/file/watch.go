@@ -2,9 +2,13 @@ package file
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/netdata/go-orchestrator/job/confgroup"
@@ -15,20 +19,48 @@ import (
 
 type (
 	Watcher struct {
-		paths        []string
+		paths []string
+		// excludes are the exclusion patterns, see IsExcluded.
+		excludes     []string
 		reg          confgroup.Registry
 		watcher      *fsnotify.Watcher
 		cache        cache
 		refreshEvery time.Duration
+		// pollEvery is the refresh interval in the polling mode.
+		pollEvery    time.Duration
+		forcePolling bool
+		mode         atomic.Value
+		newWatcher   func() (*fsnotify.Watcher, error)
+		// linkDirs are the watched dirs of the symlinked files targets.
+		linkDirs map[string]bool
 		*logger.Logger
 	}
-	cache map[string]time.Time
+	cache      map[string]cacheEntry
+	cacheEntry struct {
+		modTime time.Time
+		// realPath is the file path with symlinks resolved, it is changed by a symlink swap.
+		realPath string
+	}
 )
 
-func (c cache) lookup(path string) (time.Time, bool) { v, ok := c[path]; return v, ok }
-func (c cache) has(path string) bool                 { _, ok := c.lookup(path); return ok }
-func (c cache) remove(path string)                   { delete(c, path) }
-func (c cache) put(path string, modTime time.Time)   { c[path] = modTime }
+func (c cache) lookup(path string) (cacheEntry, bool) { v, ok := c[path]; return v, ok }
+func (c cache) has(path string) bool                  { _, ok := c.lookup(path); return ok }
+func (c cache) remove(path string)                    { delete(c, path) }
+func (c cache) put(path string, entry cacheEntry)     { c[path] = entry }
+
+func (e cacheEntry) isSymlinked(path string) bool {
+	return e.realPath != filepath.Clean(path)
+}
+
+func (e cacheEntry) equal(other cacheEntry) bool {
+	return e.modTime.Equal(other.modTime) && e.realPath == other.realPath
+}
+
+// Watcher modes.
+const (
+	ModeInotify = "inotify"
+	ModePolling = "polling"
+)
 
 func NewWatcher(reg confgroup.Registry, paths []string) *Watcher {
 	d := &Watcher{
@@ -37,28 +69,68 @@ func NewWatcher(reg confgroup.Registry, paths []string) *Watcher {
 		watcher:      nil,
 		cache:        make(cache),
 		refreshEvery: time.Minute,
+		pollEvery:    time.Second * 10,
+		linkDirs:     make(map[string]bool),
+		newWatcher:   fsnotify.NewWatcher,
 		Logger:       logger.New("discovery", "file watcher"),
 	}
+	d.mode.Store("")
 	return d
 }
 
-func (w Watcher) String() string {
+func (w *Watcher) String() string {
 	return "file watcher"
 }
 
+// Mode returns the active mode: ModeInotify or ModePolling, it is empty if the watcher is not running.
+func (w *Watcher) Mode() string {
+	return w.mode.Load().(string)
+}
+
+// Run watches the files using inotify. If inotify is not available (the instances or the watches limit is reached)
+// it falls back to polling the files and switches back to inotify once it becomes available.
 func (w *Watcher) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 	w.Info("instance is started")
 	defer func() { w.Info("instance is stopped") }()
+	defer w.mode.Store("")
 
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		w.Errorf("fsnotify watcher initialization: %v", err)
+	if w.forcePolling {
+		w.runPolling(ctx, in, false)
 		return
 	}
 
+	for {
+		err := w.runInotify(ctx, in)
+		if ctx.Err() != nil {
+			return
+		}
+		w.Warningf("inotify is not available (%v), falling back to polling", err)
+
+		w.runPolling(ctx, in, true)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// runInotify returns nil when ctx is done, otherwise an error meaning inotify is not available.
+func (w *Watcher) runInotify(ctx context.Context, in chan<- []*confgroup.Group) error {
+	watcher, err := w.newWatcher()
+	if err != nil {
+		return fmt.Errorf("fsnotify watcher initialization: %v", err)
+	}
+
 	w.watcher = watcher
-	defer w.stop()
-	w.refresh(ctx, in)
+	defer func() {
+		w.stop()
+		w.watcher = nil
+		w.linkDirs = make(map[string]bool)
+	}()
+
+	w.setMode(ModeInotify)
+	if err := w.refresh(ctx, in); err != nil {
+		return err
+	}
 
 	tk := time.NewTicker(w.refreshEvery)
 	defer tk.Stop()
@@ -66,11 +138,19 @@ func (w *Watcher) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		case <-tk.C:
-			w.refresh(ctx, in)
+			if err := w.refresh(ctx, in); err != nil {
+				return err
+			}
 		case event := <-w.watcher.Events:
-			if event.Name == "" || isChmod(event) || !w.fileMatches(event.Name) {
+			if event.Name == "" || isChmod(event) {
+				break
+			}
+			if !w.fileMatches(event.Name) && !w.hasSymlinks() && !w.isNewDir(event) {
+				// other files events matter only for symlinked files: their targets may be changed
+				// or the symlink swapped (k8s ConfigMap volumes atomically replace the '..data' dir symlink).
+				// New subdirectories matter for the recursive ('**') patterns, they should be watched.
 				break
 			}
 			if isCreate(event) && w.cache.has(event.Name) {
@@ -84,7 +164,9 @@ func (w *Watcher) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 				// This is cheap attempt to not send empty group for the old file.
 				time.Sleep(time.Millisecond * 100)
 			}
-			w.refresh(ctx, in)
+			if err := w.refresh(ctx, in); err != nil {
+				return err
+			}
 		case err := <-w.watcher.Errors:
 			if err != nil {
 				w.Warningf("watch: %v", err)
@@ -93,49 +175,136 @@ func (w *Watcher) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 	}
 }
 
+// runPolling refreshes the files every pollEvery, the modification time cache tells the changed files.
+// If tryInotify is set it returns once inotify is available.
+func (w *Watcher) runPolling(ctx context.Context, in chan<- []*confgroup.Group, tryInotify bool) {
+	w.setMode(ModePolling)
+	_ = w.refresh(ctx, in)
+
+	tk := time.NewTicker(w.pollEvery)
+	defer tk.Stop()
+
+	var retry <-chan time.Time
+	if tryInotify {
+		retryTk := time.NewTicker(w.refreshEvery)
+		defer retryTk.Stop()
+		retry = retryTk.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+			_ = w.refresh(ctx, in)
+		case <-retry:
+			if w.inotifyAvailable() {
+				w.Info("inotify is available, switching back from polling")
+				return
+			}
+		}
+	}
+}
+
+func (w *Watcher) inotifyAvailable() bool {
+	watcher, err := w.newWatcher()
+	if err != nil {
+		return false
+	}
+	defer func() { _ = watcher.Close() }()
+
+	for _, dir := range w.dirs() {
+		if err := watcher.Add(dir); isWatchLimit(err) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Watcher) setMode(mode string) {
+	w.mode.Store(mode)
+	if mode == ModePolling {
+		w.Infof("using %s mode, polling every %s", mode, w.pollEvery)
+	} else {
+		w.Infof("using %s mode", mode)
+	}
+}
+
 func (w *Watcher) fileMatches(file string) bool {
+	if IsExcluded(w.excludes, file) {
+		return false
+	}
 	for _, pattern := range w.paths {
-		if ok, _ := filepath.Match(pattern, file); ok {
+		if ok, _ := Match(pattern, file); ok {
 			return true
 		}
 	}
 	return false
 }
 
+func (w *Watcher) isNewDir(event fsnotify.Event) bool {
+	if !isCreate(event) {
+		return false
+	}
+	for _, pattern := range w.paths {
+		if isRecursive(pattern) {
+			fi, err := os.Stat(event.Name)
+			return err == nil && fi.IsDir()
+		}
+	}
+	return false
+}
+
 func (w *Watcher) listFiles() (files []string) {
 	for _, pattern := range w.paths {
-		if matches, err := filepath.Glob(pattern); err == nil {
-			files = append(files, matches...)
+		matches, err := Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, file := range matches {
+			if !IsExcluded(w.excludes, file) {
+				files = append(files, file)
+			}
 		}
 	}
 	return files
 }
 
-func (w *Watcher) refresh(ctx context.Context, in chan<- []*confgroup.Group) {
+// refresh sends groups of the changed files and updates the watches (in the inotify mode).
+// The returned error means the inotify watches limit is reached.
+func (w *Watcher) refresh(ctx context.Context, in chan<- []*confgroup.Group) error {
 	select {
 	case <-ctx.Done():
-		return
+		return nil
 	default:
 	}
 	var groups []*confgroup.Group
 	seen := make(map[string]bool)
 
 	for _, file := range w.listFiles() {
-		fi, err := os.Lstat(file)
+		// symlinks are followed, the file is re-read if its target is changed.
+		fi, err := os.Stat(file)
 		if err != nil {
-			w.Warningf("lstat '%s': %v", file, err)
+			if !os.IsNotExist(err) {
+				w.Warningf("stat '%s': %v", file, err)
+			}
 			continue
 		}
-
 		if !fi.Mode().IsRegular() {
 			continue
 		}
+		realPath, err := filepath.EvalSymlinks(file)
+		if err != nil {
+			w.Warningf("resolve '%s': %v", file, err)
+			continue
+		}
 
 		seen[file] = true
-		if v, ok := w.cache.lookup(file); ok && v.Equal(fi.ModTime()) {
+		entry := cacheEntry{modTime: fi.ModTime(), realPath: realPath}
+		if v, ok := w.cache.lookup(file); ok && v.equal(entry) {
 			continue
 		}
-		w.cache.put(file, fi.ModTime())
+		w.cache.put(file, entry)
 
 		if group, err := parse(w.reg, file); err != nil {
 			w.Warningf("parse '%s': %v", file, err)
@@ -162,32 +331,92 @@ func (w *Watcher) refresh(ctx context.Context, in chan<- []*confgroup.Group) {
 	}
 
 	send(ctx, in, groups)
-	w.watchDirs()
+	if w.watcher == nil {
+		return nil
+	}
+	return w.watchDirs()
 }
 
-func (w *Watcher) watchDirs() {
+// dirs returns the dirs to watch: the patterns dirs, all the (not excluded) subdirectories for the recursive patterns.
+func (w *Watcher) dirs() []string {
+	var dirs []string
 	for _, path := range w.paths {
+		if isRecursive(path) {
+			dirs = append(dirs, walkDirs(staticBase(path), w.excludes)...)
+			continue
+		}
 		if idx := strings.LastIndex(path, "/"); idx > -1 {
 			path = path[:idx]
 		} else {
 			path = "./"
 		}
+		dirs = append(dirs, path)
+	}
+	return dirs
+}
+
+func (w *Watcher) watchDirs() error {
+	dirs := make(map[string]bool)
+	for _, path := range w.dirs() {
+		dirs[filepath.Clean(path)] = true
 		if err := w.watcher.Add(path); err != nil {
+			if isWatchLimit(err) {
+				return fmt.Errorf("start watching '%s': %v", path, err)
+			}
 			w.Errorf("start watching '%s': %v", path, err)
 		}
 	}
+
+	// the symlinked files targets are modified in their dirs.
+	linkDirs := make(map[string]bool)
+	for path, entry := range w.cache {
+		if dir := filepath.Dir(entry.realPath); entry.isSymlinked(path) && !dirs[dir] {
+			linkDirs[dir] = true
+		}
+	}
+	for dir := range linkDirs {
+		if w.linkDirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			if isWatchLimit(err) {
+				return fmt.Errorf("start watching '%s': %v", dir, err)
+			}
+			w.Warningf("start watching '%s': %v", dir, err)
+			continue
+		}
+		w.linkDirs[dir] = true
+	}
+	for dir := range w.linkDirs {
+		if !linkDirs[dir] {
+			// the dir may be already removed (symlink swap), the watch is removed with it.
+			_ = w.watcher.Remove(dir)
+			delete(w.linkDirs, dir)
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) hasSymlinks() bool {
+	for path, entry := range w.cache {
+		if entry.isSymlinked(path) {
+			return true
+		}
+	}
+	return false
 }
 
 func (w *Watcher) stop() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	watcher := w.watcher
 	// closing the watcher deadlocks unless all events and errors are drained.
 	go func() {
 		for {
 			select {
-			case <-w.watcher.Errors:
-			case <-w.watcher.Events:
+			case <-watcher.Errors:
+			case <-watcher.Events:
 			case <-ctx.Done():
 				return
 			}
@@ -195,7 +424,12 @@ func (w *Watcher) stop() {
 	}()
 
 	// in fact never returns an error
-	_ = w.watcher.Close()
+	_ = watcher.Close()
+}
+
+// isWatchLimit returns whether the error is the inotify watches limit (ENOSPC) or the open files limit (EMFILE).
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
 }
 
 func isChmod(event fsnotify.Event) bool {
@@ -1,13 +1,19 @@
 package file
 
 import (
+	"context"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
 	"github.com/netdata/go-orchestrator/module"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWatcher_String(t *testing.T) {
@@ -336,6 +342,258 @@ func TestWatcher_Run(t *testing.T) {
 			}
 			return sim
 		},
+		"polling mode, change file": func(tmp *tmpDir) discoverySim {
+			reg := confgroup.Registry{
+				"module": {},
+			}
+			cfgOrig := sdConfig{
+				{
+					"name":   "name",
+					"module": "module",
+				},
+			}
+			cfgChanged := sdConfig{
+				{
+					"name":   "name_changed",
+					"module": "module",
+				},
+			}
+			filename := tmp.join("module.conf")
+			discovery := prepareDiscovery(t, Config{
+				Registry:  reg,
+				Watch:     []string{tmp.join("*.conf")},
+				Polling:   true,
+				PollEvery: time.Millisecond * 50,
+			})
+			expected := []*confgroup.Group{
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name_changed",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+			}
+
+			sim := discoverySim{
+				discovery: discovery,
+				beforeRun: func() {
+					tmp.writeYAML(filename, cfgOrig)
+				},
+				afterRun: func() {
+					tmp.writeYAML(filename, cfgChanged)
+				},
+				expectedGroups: expected,
+			}
+			return sim
+		},
+		"k8s ConfigMap (atomic '..data' symlink swap)": func(tmp *tmpDir) discoverySim {
+			reg := confgroup.Registry{
+				"module": {},
+			}
+			cfg := sdConfig{
+				{
+					"name":   "name",
+					"module": "module",
+				},
+			}
+			cfgChanged := sdConfig{
+				{
+					"name":   "name_changed",
+					"module": "module",
+				},
+			}
+			filename := tmp.join("module.conf")
+			discovery := prepareDiscovery(t, Config{
+				Registry: reg,
+				Watch:    []string{tmp.join("*.conf")},
+			})
+			expected := []*confgroup.Group{
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name_changed",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+			}
+
+			sim := discoverySim{
+				discovery: discovery,
+				beforeRun: func() {
+					tmp.mkdir("..2020_01_01")
+					tmp.writeYAML(tmp.join("..2020_01_01/module.conf"), cfg)
+					tmp.symlink("..2020_01_01", tmp.join("..data"))
+					tmp.symlink("..data/module.conf", filename)
+				},
+				afterRun: func() {
+					tmp.mkdir("..2020_01_02")
+					tmp.writeYAML(tmp.join("..2020_01_02/module.conf"), cfgChanged)
+					tmp.symlink("..2020_01_02", tmp.join("..data_tmp"))
+					tmp.renameFile(tmp.join("..data_tmp"), tmp.join("..data"))
+					tmp.removeDir("..2020_01_01")
+				},
+				expectedGroups: expected,
+			}
+			return sim
+		},
+		"symlinked file target is changed": func(tmp *tmpDir) discoverySim {
+			reg := confgroup.Registry{
+				"module": {},
+			}
+			cfg := sdConfig{
+				{
+					"name":   "name",
+					"module": "module",
+				},
+			}
+			cfgChanged := sdConfig{
+				{
+					"name":   "name_changed",
+					"module": "module",
+				},
+			}
+			filename := tmp.join("module.conf")
+			target := tmp.join("target/module.yml")
+			discovery := prepareDiscovery(t, Config{
+				Registry: reg,
+				Watch:    []string{tmp.join("*.conf")},
+			})
+			expected := []*confgroup.Group{
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name_changed",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+			}
+
+			sim := discoverySim{
+				discovery: discovery,
+				beforeRun: func() {
+					tmp.mkdir("target")
+					tmp.writeYAML(target, cfg)
+					tmp.symlink(target, filename)
+				},
+				afterRun: func() {
+					tmp.replaceYAML(target, cfgChanged)
+				},
+				expectedGroups: expected,
+			}
+			return sim
+		},
+		"recursive pattern, subdirs are created after start": func(tmp *tmpDir) discoverySim {
+			reg := confgroup.Registry{
+				"module": {},
+			}
+			cfg := sdConfig{
+				{
+					"name":   "name",
+					"module": "module",
+				},
+			}
+			filename := tmp.join("x/y/module.conf")
+			discovery := prepareDiscovery(t, Config{
+				Registry: reg,
+				Watch:    []string{tmp.join("**/*.conf")},
+				Exclude:  []string{"old"},
+			})
+			expected := []*confgroup.Group{
+				{
+					Source: filename,
+					Configs: []confgroup.Config{
+						{
+							"name":                "name",
+							"module":              "module",
+							"update_every":        module.UpdateEvery,
+							"autodetection_retry": module.AutoDetectionRetry,
+							"priority":            module.Priority,
+							"__source__":          filename,
+							"__provider__":        "file watcher",
+						},
+					},
+				},
+			}
+
+			sim := discoverySim{
+				discovery: discovery,
+				afterRun: func() {
+					tmp.mkdir("old")
+					tmp.writeYAML(tmp.join("old/module.conf"), cfg)
+					tmp.mkdir("x")
+					time.Sleep(time.Millisecond * 100)
+					tmp.mkdir("x/y")
+					time.Sleep(time.Millisecond * 100)
+					tmp.replaceYAML(filename, cfg)
+				},
+				expectedGroups: expected,
+			}
+			return sim
+		},
 	}
 
 	for name, createSim := range tests {
@@ -347,3 +605,55 @@ func TestWatcher_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestWatcher_Run_InotifyFallback(t *testing.T) {
+	tmp := newTmpDir(t, "watch-run-fallback-*")
+	defer tmp.cleanup()
+
+	filename := tmp.join("module.conf")
+	tmp.writeString(filename, "- module: module\n  name: name\n")
+
+	var mux sync.Mutex
+	available := false
+	w := NewWatcher(confgroup.Registry{"module": {}}, []string{tmp.join("*.conf")})
+	w.pollEvery = time.Millisecond * 50
+	w.refreshEvery = time.Millisecond * 100
+	w.newWatcher = func() (*fsnotify.Watcher, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		if !available {
+			return nil, syscall.EMFILE
+		}
+		return fsnotify.NewWatcher()
+	}
+	assert.Equal(t, "", w.Mode())
+
+	in := make(chan []*confgroup.Group)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx, in)
+
+	// inotify is not available, the files are polled.
+	groups := discoverytest.ReceiveGroups(t, in)
+	require.Len(t, groups, 1)
+	assert.Equal(t, filename, groups[0].Source)
+	assert.Equal(t, ModePolling, w.Mode())
+
+	tmp.replaceString(filename, "- module: module\n  name: name_changed\n")
+	groups = discoverytest.ReceiveGroups(t, in)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "name_changed", groups[0].Configs[0].Name())
+
+	// inotify is available again.
+	mux.Lock()
+	available = true
+	mux.Unlock()
+	assert.Eventually(t, func() bool { return w.Mode() == ModeInotify }, time.Second*5, time.Millisecond*50)
+
+	filename = tmp.join("module_inotify.conf")
+	tmp.replaceString(filename, "- module: module\n  name: name_inotify\n")
+	groups = discoverytest.ReceiveGroups(t, in)
+	require.Len(t, groups, 1)
+	assert.Equal(t, filename, groups[0].Source)
+	assert.Equal(t, "name_inotify", groups[0].Configs[0].Name())
+}
@@ -0,0 +1,210 @@
+package httpsd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/file"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+const provider = "http"
+
+type Config struct {
+	discovery.ProviderConfig
+	// URLs are the polled URLs, every URL is a config group. The response is the SD format:
+	// a YAML or JSON list of job configs.
+	URLs []string
+	// RefreshEvery is the polling interval, default is 1 minute.
+	RefreshEvery time.Duration
+	// Client is the HTTP client configuration, default timeout is 5 seconds.
+	web.Client
+}
+
+func validateConfig(cfg Config) error {
+	if len(cfg.Registry) == 0 {
+		return errors.New("empty config registry")
+	}
+	if len(cfg.URLs) == 0 {
+		return errors.New("urls not set")
+	}
+	for _, u := range cfg.URLs {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			return fmt.Errorf("invalid url '%s': %v", u, err)
+		}
+	}
+	return nil
+}
+
+type (
+	// Discovery polls the URLs and sends a group when the URL job configs change.
+	// If a request fails or the response can't be parsed the last good group is kept.
+	Discovery struct {
+		*logger.Logger
+		reg          confgroup.Registry
+		client       *http.Client
+		refreshEvery time.Duration
+		targets      []*target
+	}
+	target struct {
+		url          string
+		etag         string
+		lastModified string
+		// body is the last good response body.
+		body []byte
+	}
+)
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("http discovery config validation: %v", err)
+	}
+	if cfg.RefreshEvery <= 0 {
+		cfg.RefreshEvery = time.Minute
+	}
+	if cfg.Timeout.Duration <= 0 {
+		cfg.Timeout.Duration = time.Second * 5
+	}
+
+	client, err := web.NewHTTPClient(cfg.Client)
+	if err != nil {
+		return nil, fmt.Errorf("http discovery initialization: %v", err)
+	}
+
+	d := &Discovery{
+		Logger:       cfg.Loggers.New("discovery", "http"),
+		reg:          cfg.Registry,
+		client:       client,
+		refreshEvery: cfg.RefreshEvery,
+	}
+	for _, u := range cfg.URLs {
+		d.targets = append(d.targets, &target{url: u})
+	}
+	return d, nil
+}
+
+// NewProvider returns a discovery provider of http discoveries.
+func NewProvider(cfg Config) discovery.Provider {
+	return discovery.NewProvider(func(pcfg discovery.ProviderConfig) (discovery.Discoverer, error) {
+		cfg := cfg
+		cfg.ProviderConfig = pcfg
+		return NewDiscovery(cfg)
+	})
+}
+
+func (d Discovery) String() string {
+	return "http discovery"
+}
+
+func (d *Discovery) Run(ctx context.Context, in chan<- []*confgroup.Group) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	tk := time.NewTicker(d.refreshEvery)
+	defer tk.Stop()
+
+	for {
+		if groups := d.refresh(ctx); len(groups) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case in <- groups:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+		}
+	}
+}
+
+// refresh polls the URLs and returns groups of the changed ones.
+func (d *Discovery) refresh(ctx context.Context) []*confgroup.Group {
+	var groups []*confgroup.Group
+	for _, t := range d.targets {
+		group, err := d.poll(ctx, t)
+		if err != nil {
+			d.Warningf("'%s': %v, keeping the last good result", t.url, err)
+			continue
+		}
+		if group != nil {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// poll returns nil group if the URL job configs are not changed.
+func (d *Discovery) poll(ctx context.Context, t *target) (*confgroup.Group, error) {
+	req, err := http.NewRequest(http.MethodGet, t.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.etag != "" {
+		req.Header.Set("If-None-Match", t.etag)
+	}
+	if t.lastModified != "" {
+		req.Header.Set("If-Modified-Since", t.lastModified)
+	}
+
+	resp, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("returned HTTP status code %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if t.body != nil && bytes.Equal(body, t.body) {
+		t.setValidators(resp)
+		return nil, nil
+	}
+
+	group, err := file.ParseSD(d.reg, t.url, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range group.Configs {
+		cfg.SetSource(group.Source)
+		cfg.SetProvider(provider)
+	}
+
+	t.body = body
+	t.setValidators(resp)
+	return group, nil
+}
+
+// setValidators keeps the response validators for the next conditional request.
+func (t *target) setValidators(resp *http.Response) {
+	t.etag = resp.Header.Get("ETag")
+	t.lastModified = resp.Header.Get("Last-Modified")
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,189 @@
+package httpsd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: Config{
+				ProviderConfig: discovery.ProviderConfig{Registry: confgroup.Registry{"module1": confgroup.Default{}}},
+				URLs:           []string{"http://127.0.0.1:8080/jobs"},
+			},
+		},
+		"invalid config, registry not set": {
+			cfg:     Config{URLs: []string{"http://127.0.0.1:8080/jobs"}},
+			wantErr: true,
+		},
+		"invalid config, urls not set": {
+			cfg:     Config{ProviderConfig: discovery.ProviderConfig{Registry: confgroup.Registry{"module1": confgroup.Default{}}}},
+			wantErr: true,
+		},
+		"invalid config, invalid url": {
+			cfg: Config{
+				ProviderConfig: discovery.ProviderConfig{Registry: confgroup.Registry{"module1": confgroup.Default{}}},
+				URLs:           []string{"127.0.0.1:8080/jobs"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscovery(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscovery_refresh(t *testing.T) {
+	tests := map[string]struct {
+		etag         string
+		lastModified string
+	}{
+		"etag":          {etag: `"v1"`},
+		"last modified": {lastModified: "Wed, 21 Oct 2020 07:28:00 GMT"},
+		"no validators": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := &fakeServer{
+				body:         "- module: module1\n  name: job1\n- module: unknown\n",
+				etag:         test.etag,
+				lastModified: test.lastModified,
+			}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+			url := ts.URL + "/jobs"
+
+			d, err := NewDiscovery(Config{
+				ProviderConfig: discovery.ProviderConfig{Registry: confgroup.Registry{"module1": confgroup.Default{}}},
+				URLs:           []string{url},
+			})
+			require.NoError(t, err)
+
+			// first poll.
+			assert.Equal(t, []*confgroup.Group{
+				{Source: url, Configs: []confgroup.Config{discoverytest.NewConfig("module1", "job1", confgroup.Config{"__source__": url, "__provider__": "http"})}},
+			}, d.refresh(context.Background()))
+
+			// not changed.
+			assert.Empty(t, d.refresh(context.Background()))
+			if test.etag != "" || test.lastModified != "" {
+				assert.Equal(t, 1, srv.notModified())
+			}
+
+			// server error, the last good result is kept.
+			srv.set(http.StatusInternalServerError, "", `"v2"`, "")
+			assert.Empty(t, d.refresh(context.Background()))
+
+			// invalid response, the last good result is kept.
+			srv.set(http.StatusOK, "jobs: [", `"v3"`, "")
+			assert.Empty(t, d.refresh(context.Background()))
+
+			// changed.
+			srv.set(http.StatusOK, `[{"module": "module1", "name": "job2"}]`, test.etag+"2", "")
+			assert.Equal(t, []*confgroup.Group{
+				{Source: url, Configs: []confgroup.Config{discoverytest.NewConfig("module1", "job2", confgroup.Config{"__source__": url, "__provider__": "http"})}},
+			}, d.refresh(context.Background()))
+
+			// all jobs removed.
+			srv.set(http.StatusOK, "", "", "")
+			assert.Equal(t, []*confgroup.Group{{Source: url}}, d.refresh(context.Background()))
+		})
+	}
+}
+
+func TestDiscovery_Run(t *testing.T) {
+	srv := &fakeServer{body: "- module: module1\n  name: job1\n"}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	d, err := NewDiscovery(Config{
+		ProviderConfig: discovery.ProviderConfig{Registry: confgroup.Registry{"module1": confgroup.Default{}}},
+		URLs:           []string{ts.URL},
+		RefreshEvery:   time.Millisecond * 100,
+	})
+	require.NoError(t, err)
+
+	in := make(chan []*confgroup.Group)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, in)
+
+	assert.Equal(t, []*confgroup.Group{
+		{Source: ts.URL, Configs: []confgroup.Config{discoverytest.NewConfig("module1", "job1", confgroup.Config{"__source__": ts.URL, "__provider__": "http"})}},
+	}, discoverytest.ReceiveGroups(t, in))
+
+	srv.set(http.StatusOK, "- module: module1\n  name: job2\n", "", "")
+	assert.Equal(t, []*confgroup.Group{
+		{Source: ts.URL, Configs: []confgroup.Config{discoverytest.NewConfig("module1", "job2", confgroup.Config{"__source__": ts.URL, "__provider__": "http"})}},
+	}, discoverytest.ReceiveGroups(t, in))
+}
+
+type fakeServer struct {
+	mux          sync.Mutex
+	code         int
+	body         string
+	etag         string
+	lastModified string
+	notModCount  int
+}
+
+func (s *fakeServer) set(code int, body, etag, lastModified string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.code, s.body, s.etag, s.lastModified = code, body, etag, lastModified
+}
+
+func (s *fakeServer) notModified() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.notModCount
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.code != 0 && s.code != http.StatusOK {
+		w.WriteHeader(s.code)
+		return
+	}
+	if (s.etag != "" && r.Header.Get("If-None-Match") == s.etag) ||
+		(s.lastModified != "" && r.Header.Get("If-Modified-Since") == s.lastModified) {
+		s.notModCount++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if s.lastModified != "" {
+		w.Header().Set("Last-Modified", s.lastModified)
+	}
+	_, _ = w.Write([]byte(s.body))
+}
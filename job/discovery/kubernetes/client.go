@@ -0,0 +1,168 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/netdata/go-orchestrator/pkg/tlscfg"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+type (
+	apiObjectMeta struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		UID         string            `json:"uid"`
+		Labels      map[string]string `json:"labels"`
+		Annotations map[string]string `json:"annotations"`
+	}
+	apiList struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}
+	apiWatchEvent struct {
+		// Type is 'ADDED', 'MODIFIED', 'DELETED', 'BOOKMARK' or 'ERROR'.
+		Type   string          `json:"type"`
+		Object json.RawMessage `json:"object"`
+	}
+	apiStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	apiPod struct {
+		Metadata apiObjectMeta `json:"metadata"`
+		Spec     struct {
+			NodeName   string `json:"nodeName"`
+			Containers []struct {
+				Name  string `json:"name"`
+				Image string `json:"image"`
+				Ports []struct {
+					Name          string `json:"name"`
+					ContainerPort int    `json:"containerPort"`
+					Protocol      string `json:"protocol"`
+				} `json:"ports"`
+			} `json:"containers"`
+		} `json:"spec"`
+		Status struct {
+			Phase string `json:"phase"`
+			PodIP string `json:"podIP"`
+		} `json:"status"`
+	}
+	apiService struct {
+		Metadata apiObjectMeta `json:"metadata"`
+		Spec     struct {
+			Type      string `json:"type"`
+			ClusterIP string `json:"clusterIP"`
+			Ports     []struct {
+				Name     string `json:"name"`
+				Port     int    `json:"port"`
+				Protocol string `json:"protocol"`
+			} `json:"ports"`
+		} `json:"spec"`
+	}
+)
+
+// apiClient is a minimal Kubernetes API client, it lists and watches the core (v1) API resources.
+type apiClient struct {
+	httpClient *http.Client
+	address    string
+	tokenFile  string
+	timeout    time.Duration
+}
+
+func newAPIClient(address, tokenFile string, tlsConfig tlscfg.TLSConfig, timeout time.Duration) (*apiClient, error) {
+	if _, err := url.Parse(address); err != nil {
+		return nil, fmt.Errorf("parse address '%s': %v", address, err)
+	}
+	// there is no client timeout, watch streams are read until they are closed.
+	httpClient, err := web.NewHTTPClient(web.Client{TLSConfig: tlsConfig})
+	if err != nil {
+		return nil, err
+	}
+	client := &apiClient{
+		httpClient: httpClient,
+		address:    strings.TrimSuffix(address, "/"),
+		tokenFile:  tokenFile,
+		timeout:    timeout,
+	}
+	return client, nil
+}
+
+// list returns the resource objects, path is the resource path, e.g. '/api/v1/namespaces/default/pods'.
+func (c *apiClient) list(ctx context.Context, path string) (*apiList, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.get(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	var list apiList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decode '%s' list: %v", path, err)
+	}
+	return &list, nil
+}
+
+// watch returns the resource watch events stream starting after the resource version.
+// It is closed when ctx is done or by the API server (the watch timeout).
+func (c *apiClient) watch(ctx context.Context, path, resourceVersion string) (io.ReadCloser, error) {
+	query := url.Values{
+		"watch":           {"true"},
+		"resourceVersion": {resourceVersion},
+	}
+	resp, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *apiClient) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.address + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokenFile != "" {
+		// the token is read on every request, service account tokens are rotated.
+		token, err := ioutil.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read bearer token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+string(bytes.TrimSpace(token)))
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("GET '%s': %v", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		closeBody(resp)
+		return nil, fmt.Errorf("GET '%s': returned HTTP status code %d", path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
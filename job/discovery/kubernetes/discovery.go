@@ -0,0 +1,298 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+	"github.com/netdata/go-orchestrator/pkg/tlscfg"
+)
+
+const (
+	RolePod     = "pod"
+	RoleService = "service"
+)
+
+const (
+	provider = "k8s"
+
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+type Config struct {
+	discovery.ProviderConfig
+	// ComposeConfig creates job configs for the discovered objects, the target is PodTarget or ServiceTarget.
+	pipeline.ComposeConfig
+	// Role is the kind of the discovered objects: RolePod or RoleService.
+	Role string
+	// Namespaces are the namespaces to discover, all namespaces if not set.
+	Namespaces []string
+	// Address is the API server URL. If it is not set the in-cluster address
+	// and the service account bearer token and CA are used.
+	Address         string
+	BearerTokenFile string
+	tlscfg.TLSConfig
+	// Timeout is the list requests timeout, default is 5 seconds.
+	Timeout time.Duration
+}
+
+func validateConfig(cfg Config) error {
+	if cfg.Role != RolePod && cfg.Role != RoleService {
+		return fmt.Errorf("unknown role: '%s'", cfg.Role)
+	}
+	if cfg.Address == "" && os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return errors.New("address not set and not inside a k8s cluster")
+	}
+	return nil
+}
+
+// Discovery lists and watches pods or services, every object is a target group.
+// Pods that are not running (no IP) have no targets, groups are removed when objects are deleted.
+type Discovery struct {
+	*logger.Logger
+	role       string
+	namespaces []string
+	client     *apiClient
+	retryEvery time.Duration
+	// relistEvery is the objects minimum re-listing interval, it limits the requests rate
+	// if the API server closes the watches right away.
+	relistEvery time.Duration
+}
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("k8s discovery config validation: %v", err)
+	}
+	if cfg.Address == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		cfg.Address = "https://" + net.JoinHostPort(host, port)
+		if cfg.BearerTokenFile == "" {
+			cfg.BearerTokenFile = serviceAccountTokenFile
+		}
+		if cfg.TLSCA == "" {
+			cfg.TLSCA = serviceAccountCAFile
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second * 5
+	}
+	if len(cfg.Namespaces) == 0 {
+		cfg.Namespaces = []string{""}
+	}
+
+	client, err := newAPIClient(cfg.Address, cfg.BearerTokenFile, cfg.TLSConfig, cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("k8s discovery initialization: %v", err)
+	}
+
+	d := &Discovery{
+		Logger:      cfg.Loggers.New("discovery", "k8s "+cfg.Role),
+		role:        cfg.Role,
+		namespaces:  cfg.Namespaces,
+		client:      client,
+		retryEvery:  time.Second * 10,
+		relistEvery: time.Second * 5,
+	}
+	return d, nil
+}
+
+// NewProvider returns a discovery provider of k8s discoveries.
+func NewProvider(cfg Config) discovery.Provider {
+	return discovery.NewTargetProvider(cfg.ComposeConfig, func(pcfg discovery.ProviderConfig) (pipeline.TargetDiscoverer, error) {
+		cfg := cfg
+		cfg.ProviderConfig = pcfg
+		return NewDiscovery(cfg)
+	})
+}
+
+func (d Discovery) String() string {
+	return fmt.Sprintf("k8s %s discovery", d.role)
+}
+
+// Run watches the objects of every namespace. The objects are re-listed when the watch ends
+// (not more often than every 5 seconds), if the API is not available it retries.
+func (d *Discovery) Run(ctx context.Context, in chan<- []*pipeline.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	var wg sync.WaitGroup
+	for _, ns := range d.namespaces {
+		wg.Add(1)
+		go func(ns string) { defer wg.Done(); d.runNamespace(ctx, ns, in) }(ns)
+	}
+	wg.Wait()
+}
+
+func (d *Discovery) runNamespace(ctx context.Context, namespace string, in chan<- []*pipeline.TargetGroup) {
+	path := d.resourcePath(namespace)
+	// sources are the sent non-empty groups sources, they are removed if they are gone after re-listing.
+	sources := make(map[string]bool)
+
+	for {
+		listed := time.Now()
+		err := d.watch(ctx, path, sources, in)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := d.retryEvery
+		if err == nil {
+			if delay = d.relistEvery - time.Since(listed); delay <= 0 {
+				continue
+			}
+		} else {
+			d.Warningf("%v, retrying in %s", err, d.retryEvery)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// watch lists the objects and watches them until the watch is closed, nil error means the objects should be re-listed.
+func (d *Discovery) watch(ctx context.Context, path string, sources map[string]bool, in chan<- []*pipeline.TargetGroup) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	list, err := d.client.list(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	var groups []*pipeline.TargetGroup
+	seen := make(map[string]bool)
+	for _, item := range list.Items {
+		group, err := d.newGroup(item)
+		if err != nil {
+			d.Warning(err)
+			continue
+		}
+		seen[group.Source] = true
+		groups = append(groups, group)
+	}
+	for source := range sources {
+		if !seen[source] {
+			groups = append(groups, &pipeline.TargetGroup{Source: source})
+		}
+	}
+	if !d.send(ctx, sources, in, groups) {
+		return nil
+	}
+
+	stream, err := d.client.watch(ctx, path, list.Metadata.ResourceVersion)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+
+	dec := json.NewDecoder(stream)
+	for {
+		var e apiWatchEvent
+		if err := dec.Decode(&e); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read '%s' watch events: %v", path, err)
+		}
+
+		var group *pipeline.TargetGroup
+		switch e.Type {
+		case "ADDED", "MODIFIED":
+			group, err = d.newGroup(e.Object)
+		case "DELETED":
+			group, err = d.newGroup(e.Object)
+			if group != nil {
+				if !sources[group.Source] {
+					continue
+				}
+				group.Targets = nil
+			}
+		case "ERROR":
+			var status apiStatus
+			_ = json.Unmarshal(e.Object, &status)
+			if status.Code == http.StatusGone {
+				// the resource version is too old.
+				return nil
+			}
+			return fmt.Errorf("'%s' watch error: %s (code %d)", path, status.Message, status.Code)
+		}
+		if err != nil {
+			d.Warning(err)
+			continue
+		}
+		if group != nil && !d.send(ctx, sources, in, []*pipeline.TargetGroup{group}) {
+			return nil
+		}
+	}
+}
+
+func (d *Discovery) newGroup(obj json.RawMessage) (*pipeline.TargetGroup, error) {
+	var source string
+	var target interface{}
+
+	switch d.role {
+	case RolePod:
+		var pod apiPod
+		if err := json.Unmarshal(obj, &pod); err != nil {
+			return nil, fmt.Errorf("decode pod: %v", err)
+		}
+		source = d.source(pod.Metadata)
+		if pod.Status.PodIP == "" || pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+			return &pipeline.TargetGroup{Source: source}, nil
+		}
+		target = newPodTarget(pod)
+	case RoleService:
+		var svc apiService
+		if err := json.Unmarshal(obj, &svc); err != nil {
+			return nil, fmt.Errorf("decode service: %v", err)
+		}
+		source = d.source(svc.Metadata)
+		target = newServiceTarget(svc)
+	}
+
+	return &pipeline.TargetGroup{Source: source, Targets: []interface{}{target}}, nil
+}
+
+func (d *Discovery) send(ctx context.Context, sources map[string]bool, in chan<- []*pipeline.TargetGroup, groups []*pipeline.TargetGroup) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case in <- groups:
+	}
+	for _, group := range groups {
+		if len(group.Targets) == 0 {
+			delete(sources, group.Source)
+		} else {
+			sources[group.Source] = true
+		}
+	}
+	return true
+}
+
+func (d *Discovery) source(meta apiObjectMeta) string {
+	return fmt.Sprintf("%s/%s/%s/%s", provider, d.role, meta.Namespace, meta.Name)
+}
+
+func (d *Discovery) resourcePath(namespace string) string {
+	resource := d.role + "s"
+	if namespace == "" {
+		return "/api/v1/" + resource
+	}
+	return fmt.Sprintf("/api/v1/namespaces/%s/%s", namespace, resource)
+}
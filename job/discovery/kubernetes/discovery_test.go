@@ -0,0 +1,271 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: Config{Role: RolePod, Address: "http://127.0.0.1:8001"},
+		},
+		"invalid config, unknown role": {
+			cfg:     Config{Role: "node", Address: "http://127.0.0.1:8001"},
+			wantErr: true,
+		},
+		"invalid config, address not set outside of k8s cluster": {
+			cfg:     Config{Role: RolePod},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscovery(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: Config{
+				Role:          RoleService,
+				Address:       "http://127.0.0.1:8001",
+				ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: nginx"}}},
+			},
+		},
+		"invalid config, templates not set": {
+			cfg:     Config{Role: RoleService, Address: "http://127.0.0.1:8001"},
+			wantErr: true,
+		},
+		"invalid config, unknown role": {
+			cfg: Config{
+				Role:          "node",
+				Address:       "http://127.0.0.1:8001",
+				ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: nginx"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewProvider(test.cfg)(discovery.ProviderConfig{
+				Registry: confgroup.Registry{"nginx": confgroup.Default{}},
+			})
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscovery_Run_Pods(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-discovery-k8s")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("secret\n"), 0644))
+
+	api := newFakeAPIServer("/api/v1/namespaces/default/pods", "secret",
+		testPodJSON("web", "10.0.0.1", "nginx:1.19", 80),
+		testPodJSON("db", "10.0.0.2", "mysql:8", 3306),
+	)
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	d, err := NewProvider(Config{
+		Role:            RolePod,
+		Namespaces:      []string{"default"},
+		Address:         srv.URL,
+		BearerTokenFile: tokenFile,
+		ComposeConfig: pipeline.ComposeConfig{
+			Templates: []tmpl.Template{
+				{
+					Selector: `{{ glob (index .Containers 0).Image "nginx*" }}`,
+					Config:   "module: nginx\nname: {{.Name}}\nurl: {{ quote (printf \"http://%s:%d\" .IP (index (index .Containers 0).Ports 0).Port) }}",
+				},
+			},
+		},
+	})(discovery.ProviderConfig{Registry: confgroup.Registry{"nginx": confgroup.Default{}}})
+	require.NoError(t, err)
+
+	in := make(chan []*confgroup.Group)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, in)
+
+	// listed pods.
+	assert.Equal(t, []*confgroup.Group{
+		{Source: "k8s/pod/default/web", Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "web", confgroup.Config{"url": "http://10.0.0.1:80", "__source__": "k8s/pod/default/web"})}},
+		{Source: "k8s/pod/default/db"},
+	}, discoverytest.ReceiveGroups(t, in))
+
+	// pod is created, it is not running yet.
+	api.events <- fmt.Sprintf(`{"type":"ADDED","object":%s}`, testPodJSON("proxy", "", "nginx:1.18", 8080))
+	assert.Equal(t, []*confgroup.Group{{Source: "k8s/pod/default/proxy"}}, discoverytest.ReceiveGroups(t, in))
+
+	// pod is running.
+	api.events <- fmt.Sprintf(`{"type":"MODIFIED","object":%s}`, testPodJSON("proxy", "10.0.0.3", "nginx:1.18", 8080))
+	assert.Equal(t, []*confgroup.Group{
+		{Source: "k8s/pod/default/proxy", Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "proxy", confgroup.Config{"url": "http://10.0.0.3:8080", "__source__": "k8s/pod/default/proxy"})}},
+	}, discoverytest.ReceiveGroups(t, in))
+
+	// pod is deleted.
+	api.events <- fmt.Sprintf(`{"type":"DELETED","object":%s}`, testPodJSON("web", "10.0.0.1", "nginx:1.19", 80))
+	assert.Equal(t, []*confgroup.Group{{Source: "k8s/pod/default/web"}}, discoverytest.ReceiveGroups(t, in))
+}
+
+func TestDiscovery_Run_Services(t *testing.T) {
+	api := newFakeAPIServer("/api/v1/services", "",
+		`{"metadata":{"name":"web","namespace":"default","labels":{"app":"nginx"}},
+"spec":{"type":"ClusterIP","clusterIP":"10.96.0.10","ports":[{"name":"http","port":80,"protocol":"TCP"}]}}`,
+	)
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	d, err := NewProvider(Config{
+		Role:    RoleService,
+		Address: srv.URL,
+		ComposeConfig: pipeline.ComposeConfig{
+			Templates: []tmpl.Template{
+				{
+					Selector: `{{ eq .Labels.app "nginx" }}`,
+					Config:   "{{range .Ports}}- module: nginx\n  name: {{$.Name}}\n  url: {{ quote (printf \"http://%s:%d\" $.ClusterIP .Port) }}\n{{end}}",
+				},
+			},
+		},
+	})(discovery.ProviderConfig{Registry: confgroup.Registry{"nginx": confgroup.Default{}}})
+	require.NoError(t, err)
+
+	in := make(chan []*confgroup.Group)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, in)
+
+	assert.Equal(t, []*confgroup.Group{
+		{Source: "k8s/service/default/web", Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "web", confgroup.Config{"url": "http://10.96.0.10:80", "__source__": "k8s/service/default/web"})}},
+	}, discoverytest.ReceiveGroups(t, in))
+}
+
+func TestDiscovery_Run_WatchClosed(t *testing.T) {
+	api := newFakeAPIServer("/api/v1/namespaces/default/pods", "",
+		testPodJSON("web", "10.0.0.1", "nginx:1.19", 80),
+	)
+	api.closeWatch = true
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	d, err := NewDiscovery(Config{Role: RolePod, Namespaces: []string{"default"}, Address: srv.URL})
+	require.NoError(t, err)
+	d.relistEvery = time.Millisecond * 200
+
+	in := make(chan []*pipeline.TargetGroup)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { defer close(done); d.Run(ctx, in) }()
+	go func() {
+		for range in {
+		}
+	}()
+
+	time.Sleep(time.Second)
+	cancel()
+	<-done
+	close(in)
+
+	// the pods are re-listed every 200ms instead of as fast as the server closes the watches.
+	lists := atomic.LoadInt64(&api.lists)
+	assert.True(t, lists >= 2 && lists <= 6, "lists: %d", lists)
+}
+
+func testPodJSON(name, ip, image string, port int) string {
+	phase := "Running"
+	if ip == "" {
+		phase = "Pending"
+	}
+	return fmt.Sprintf(`{"metadata":{"name":"%s","namespace":"default"},
+"spec":{"nodeName":"node1","containers":[{"name":"%s","image":"%s","ports":[{"containerPort":%d,"protocol":"TCP"}]}]},
+"status":{"phase":"%s","podIP":"%s"}}`, name, name, image, port, phase, ip)
+}
+
+// fakeAPIServer is a fake Kubernetes API server of a single resource.
+type fakeAPIServer struct {
+	path   string
+	token  string
+	items  []string
+	events chan string
+	// closeWatch makes the server close the watches right away.
+	closeWatch bool
+	lists      int64
+}
+
+func newFakeAPIServer(path, token string, items ...string) *fakeAPIServer {
+	return &fakeAPIServer{path: path, token: token, items: items, events: make(chan string)}
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != s.path {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Query().Get("watch") != "true" {
+		atomic.AddInt64(&s.lists, 1)
+		_, _ = fmt.Fprintf(w, `{"metadata":{"resourceVersion":"1"},"items":[%s]}`, strings.Join(s.items, ","))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	for !s.closeWatch {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-s.events:
+			_, _ = w.Write([]byte(event + "\n"))
+			w.(http.Flusher).Flush()
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package kubernetes
+
+type (
+	// PodTarget is a running pod, it is the templates data of the 'pod' role.
+	PodTarget struct {
+		Namespace   string
+		Name        string
+		UID         string
+		NodeName    string
+		IP          string
+		Labels      map[string]string
+		Annotations map[string]string
+		Containers  []Container
+	}
+	// Container is a pod container.
+	Container struct {
+		Name  string
+		Image string
+		Ports []Port
+	}
+	// ServiceTarget is a service, it is the templates data of the 'service' role.
+	ServiceTarget struct {
+		Namespace string
+		Name      string
+		UID       string
+		// Type is the service type: 'ClusterIP', 'NodePort', 'LoadBalancer' or 'ExternalName'.
+		Type        string
+		ClusterIP   string
+		Labels      map[string]string
+		Annotations map[string]string
+		Ports       []Port
+	}
+	// Port is a container or a service port.
+	Port struct {
+		Name     string
+		Port     int
+		Protocol string
+	}
+)
+
+func newPodTarget(pod apiPod) PodTarget {
+	t := PodTarget{
+		Namespace:   pod.Metadata.Namespace,
+		Name:        pod.Metadata.Name,
+		UID:         pod.Metadata.UID,
+		NodeName:    pod.Spec.NodeName,
+		IP:          pod.Status.PodIP,
+		Labels:      orEmpty(pod.Metadata.Labels),
+		Annotations: orEmpty(pod.Metadata.Annotations),
+	}
+	for _, c := range pod.Spec.Containers {
+		container := Container{Name: c.Name, Image: c.Image}
+		for _, p := range c.Ports {
+			container.Ports = append(container.Ports, Port{Name: p.Name, Port: p.ContainerPort, Protocol: p.Protocol})
+		}
+		t.Containers = append(t.Containers, container)
+	}
+	return t
+}
+
+func newServiceTarget(svc apiService) ServiceTarget {
+	t := ServiceTarget{
+		Namespace:   svc.Metadata.Namespace,
+		Name:        svc.Metadata.Name,
+		UID:         svc.Metadata.UID,
+		Type:        svc.Spec.Type,
+		ClusterIP:   svc.Spec.ClusterIP,
+		Labels:      orEmpty(svc.Metadata.Labels),
+		Annotations: orEmpty(svc.Metadata.Annotations),
+	}
+	for _, p := range svc.Spec.Ports {
+		t.Ports = append(t.Ports, Port{Name: p.Name, Port: p.Port, Protocol: p.Protocol})
+	}
+	return t
+}
+
+func orEmpty(m map[string]string) map[string]string {
+	if m == nil {
+		return make(map[string]string)
+	}
+	return m
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -17,25 +18,46 @@ type Config struct {
 	Registry confgroup.Registry
 	File     file.Config
 	Dummy    dummy.Config
+	// Providers are additional (not built-in) discoverers, every provider discoverer is created and run.
+	Providers Providers
+	// Loggers creates the discoverers loggers, default is the logger package default factory.
+	Loggers *logger.Factory
 }
 
 func validateConfig(cfg Config) error {
 	if len(cfg.Registry) == 0 {
 		return errors.New("empty config registry")
 	}
-	if len(cfg.File.Read)+len(cfg.File.Watch) == 0 && len(cfg.Dummy.Names) == 0 {
+	if len(cfg.File.Read)+len(cfg.File.Watch) == 0 && len(cfg.Dummy.Names) == 0 && len(cfg.Providers) == 0 {
 		return errors.New("discoverers not set")
 	}
 	return nil
 }
 
 type (
-	discoverer interface {
+	// Discoverer discovers job configs. Run sends config groups to 'in' until ctx is done.
+	// A group replaces the previously sent group with the same Source, an empty group removes it.
+	// Run may close 'in' if there is nothing more to discover (e.g. it reads static files once).
+	Discoverer interface {
 		Run(ctx context.Context, in chan<- []*confgroup.Group)
 	}
+	// ProviderConfig is passed to a Provider to create its Discoverer.
+	ProviderConfig struct {
+		// Registry contains the enabled modules defaults, configs for unknown modules should be skipped
+		// and the defaults should be applied with confgroup.Config Apply.
+		Registry confgroup.Registry
+		Loggers  *logger.Factory
+	}
+	// Provider creates a Discoverer.
+	Provider func(cfg ProviderConfig) (Discoverer, error)
+	// Providers is a collection of Providers by name.
+	// The provider name is set as the provider of the discovered configs (overwriting the discoverer one),
+	// the plugin relies on it to tell the provider sources.
+	Providers map[string]Provider
+
 	Manager struct {
 		*logger.Logger
-		discoverers []discoverer
+		discoverers []Discoverer
 		send        chan struct{}
 		sendEvery   time.Duration
 		mux         *sync.RWMutex
@@ -50,10 +72,10 @@ func NewManager(cfg Config) (*Manager, error) {
 	mgr := &Manager{
 		send:        make(chan struct{}, 1),
 		sendEvery:   time.Second * 2, // some timeout to aggregate changes
-		discoverers: make([]discoverer, 0),
+		discoverers: make([]Discoverer, 0),
 		mux:         &sync.RWMutex{},
 		cache:       newCache(),
-		Logger:      logger.New("discovery", "manager"),
+		Logger:      cfg.Loggers.New("discovery", "manager"),
 	}
 	if err := mgr.registerDiscoverers(cfg); err != nil {
 		return nil, fmt.Errorf("discovery manager initializaion: %v", err)
@@ -68,6 +90,7 @@ func (m Manager) String() string {
 func (m *Manager) registerDiscoverers(cfg Config) error {
 	if len(cfg.File.Read) > 0 || len(cfg.File.Watch) > 0 {
 		cfg.File.Registry = cfg.Registry
+		cfg.File.Loggers = cfg.Loggers
 		d, err := file.NewDiscovery(cfg.File)
 		if err != nil {
 			return err
@@ -77,6 +100,7 @@ func (m *Manager) registerDiscoverers(cfg Config) error {
 
 	if len(cfg.Dummy.Names) > 0 {
 		cfg.Dummy.Registry = cfg.Registry
+		cfg.Dummy.Loggers = cfg.Loggers
 		d, err := dummy.NewDiscovery(cfg.Dummy)
 		if err != nil {
 			return err
@@ -84,6 +108,22 @@ func (m *Manager) registerDiscoverers(cfg Config) error {
 		m.discoverers = append(m.discoverers, d)
 	}
 
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d, err := cfg.Providers[name](ProviderConfig{Registry: cfg.Registry, Loggers: cfg.Loggers})
+		if err != nil {
+			return fmt.Errorf("'%s' provider: %v", name, err)
+		}
+		if d == nil {
+			return fmt.Errorf("'%s' provider: nil discoverer", name)
+		}
+		m.discoverers = append(m.discoverers, providerDiscoverer{name: name, Discoverer: d})
+	}
+
 	if len(m.discoverers) == 0 {
 		return errors.New("zero registered discoverers")
 	}
@@ -91,6 +131,54 @@ func (m *Manager) registerDiscoverers(cfg Config) error {
 	return nil
 }
 
+// Register registers a provider.
+func (p Providers) Register(name string, provider Provider) {
+	if _, ok := p[name]; ok {
+		panic(fmt.Sprintf("%s is already in providers", name))
+	}
+	p[name] = provider
+}
+
+// providerDiscoverer sets the provider name as the provider of the discovered configs.
+type providerDiscoverer struct {
+	name string
+	Discoverer
+}
+
+func (d providerDiscoverer) String() string {
+	return d.name
+}
+
+func (d providerDiscoverer) Run(ctx context.Context, in chan<- []*confgroup.Group) {
+	updates := make(chan []*confgroup.Group)
+	go d.Discoverer.Run(ctx, updates)
+
+	defer close(in)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case groups, ok := <-updates:
+			if !ok {
+				return
+			}
+			for _, group := range groups {
+				if group == nil {
+					continue
+				}
+				for _, cfg := range group.Configs {
+					cfg.SetProvider(d.name)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case in <- groups:
+			}
+		}
+	}
+}
+
 func (m *Manager) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 	m.Info("instance is started")
 	defer func() { m.Info("instance is stopped") }()
@@ -99,7 +187,7 @@ func (m *Manager) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 
 	for _, d := range m.discoverers {
 		wg.Add(1)
-		go func(d discoverer) {
+		go func(d Discoverer) {
 			defer wg.Done()
 			m.runDiscoverer(ctx, d)
 		}(d)
@@ -115,7 +203,7 @@ func (m *Manager) Run(ctx context.Context, in chan<- []*confgroup.Group) {
 	<-ctx.Done()
 }
 
-func (m *Manager) runDiscoverer(ctx context.Context, d discoverer) {
+func (m *Manager) runDiscoverer(ctx context.Context, d Discoverer) {
 	updates := make(chan []*confgroup.Group)
 	go d.Run(ctx, updates)
 
@@ -2,6 +2,7 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -25,6 +26,23 @@ func TestNewManager(t *testing.T) {
 				File:     file.Config{Read: []string{"path"}},
 			},
 		},
+		"valid config, only providers": {
+			cfg: Config{
+				Registry: confgroup.Registry{"module1": confgroup.Default{}},
+				Providers: Providers{
+					"custom": func(ProviderConfig) (Discoverer, error) { return mockDiscoverer{}, nil },
+				},
+			},
+		},
+		"invalid config, provider error": {
+			cfg: Config{
+				Registry: confgroup.Registry{"module1": confgroup.Default{}},
+				Providers: Providers{
+					"custom": func(ProviderConfig) (Discoverer, error) { return nil, errors.New("error") },
+				},
+			},
+			wantErr: true,
+		},
 		"invalid config, registry not set": {
 			cfg: Config{
 				File: file.Config{Read: []string{"path"}},
@@ -106,6 +124,42 @@ func TestManager_Run(t *testing.T) {
 			}
 			return sim
 		},
+		"provider discoverer, provider is overwritten": func() discoverySim {
+			const numGroups, numCfgs = 2, 2
+			d1 := prepareMockDiscoverer("test1", numGroups, numCfgs)
+			d1.groups[0].Configs[0].SetProvider("test1")
+			mgr, err := NewManager(Config{
+				Registry: confgroup.Registry{"module1": confgroup.Default{}},
+				Providers: Providers{
+					"custom": func(cfg ProviderConfig) (Discoverer, error) {
+						if len(cfg.Registry) == 0 {
+							return nil, errors.New("empty registry")
+						}
+						return d1, nil
+					},
+				},
+			})
+			if err != nil {
+				panic(err)
+			}
+
+			var expected []*confgroup.Group
+			for _, group := range d1.groups {
+				g := &confgroup.Group{Source: group.Source}
+				for _, cfg := range group.Configs {
+					c := confgroup.Config{"name": cfg.Name()}
+					c.SetProvider("custom")
+					g.Configs = append(g.Configs, c)
+				}
+				expected = append(expected, g)
+			}
+
+			sim := discoverySim{
+				mgr:            mgr,
+				expectedGroups: expected,
+			}
+			return sim
+		},
 		"several discoverers, nil groups": func() discoverySim {
 			const numGroups, numCfgs = 0, 0
 			d1 := prepareMockDiscoverer("test1", numGroups, numCfgs)
@@ -141,7 +195,7 @@ func prepareMockDiscoverer(source string, groups, configs int) mockDiscoverer {
 	return d
 }
 
-func prepareManager(discoverers ...discoverer) *Manager {
+func prepareManager(discoverers ...Discoverer) *Manager {
 	mgr := &Manager{
 		send:        make(chan struct{}, 1),
 		sendEvery:   2 * time.Second,
@@ -173,3 +227,13 @@ func combineGroups(groups ...[]*confgroup.Group) (combined []*confgroup.Group) {
 	}
 	return combined
 }
+
+func TestProviders_Register(t *testing.T) {
+	p := Providers{}
+	provider := func(ProviderConfig) (Discoverer, error) { return mockDiscoverer{}, nil }
+
+	p.Register("custom", provider)
+
+	assert.Len(t, p, 1)
+	assert.Panics(t, func() { p.Register("custom", provider) })
+}
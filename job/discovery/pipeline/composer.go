@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"errors"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+)
+
+// ComposeConfig is how job configs are composed for the discovered targets, the target discoverers
+// configs embed it. The Templates are used if the Rules file is not set.
+type ComposeConfig struct {
+	Templates []tmpl.Template
+	// Rules is the classify/compose rules file (see Config).
+	Rules string
+}
+
+// Composer renders job configs for a discovered target, it is a Pipeline or a tmpl.Composer.
+type Composer interface {
+	Compose(target interface{}) ([]confgroup.Config, error)
+}
+
+// NewComposer returns the Pipeline of the rules file if it is set, otherwise the templates composer.
+func NewComposer(reg confgroup.Registry, cfg ComposeConfig) (Composer, error) {
+	switch {
+	case cfg.Rules != "":
+		return Load(cfg.Rules, reg)
+	case len(cfg.Templates) != 0:
+		return tmpl.New(reg, cfg.Templates)
+	default:
+		return nil, errors.New("neither templates nor rules file is set")
+	}
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+)
+
+type (
+	// TargetGroup is a group of discovered targets (e.g. a container ports or a file targets list),
+	// the targets are the templates data. A group replaces the previously sent group with the same Source,
+	// an empty group removes it.
+	TargetGroup struct {
+		Source  string
+		Targets []interface{}
+	}
+	// TargetDiscoverer discovers targets. Run sends target groups to 'in' until ctx is done.
+	// Run may close 'in' if there is nothing more to discover.
+	TargetDiscoverer interface {
+		Run(ctx context.Context, in chan<- []*TargetGroup)
+	}
+)
+
+// Discoverer is the pipeline stage between a TargetDiscoverer and the jobs builder:
+// it sends config groups with the job configs composed for the discovered targets.
+type Discoverer struct {
+	*logger.Logger
+	targets  TargetDiscoverer
+	composer Composer
+}
+
+// NewDiscoverer creates a Discoverer, the targets job configs are composed with the compose config.
+func NewDiscoverer(reg confgroup.Registry, cfg ComposeConfig, targets TargetDiscoverer) (*Discoverer, error) {
+	if targets == nil {
+		return nil, fmt.Errorf("nil target discoverer")
+	}
+	composer, err := NewComposer(reg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Discoverer{targets: targets, composer: composer}, nil
+}
+
+func (d Discoverer) String() string {
+	return fmt.Sprintf("%v", d.targets)
+}
+
+// Run runs the target discoverer and sends the composed config groups to 'in', it closes 'in' on return.
+func (d *Discoverer) Run(ctx context.Context, in chan<- []*confgroup.Group) {
+	updates := make(chan []*TargetGroup)
+	go d.targets.Run(ctx, updates)
+
+	defer close(in)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tggs, ok := <-updates:
+			if !ok {
+				return
+			}
+			var groups []*confgroup.Group
+			for _, tgg := range tggs {
+				if tgg != nil {
+					groups = append(groups, d.compose(tgg))
+				}
+			}
+			if len(groups) == 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case in <- groups:
+			}
+		}
+	}
+}
+
+func (d *Discoverer) compose(tgg *TargetGroup) *confgroup.Group {
+	group := &confgroup.Group{Source: tgg.Source}
+	for _, target := range tgg.Targets {
+		cfgs, err := d.composer.Compose(target)
+		if err != nil {
+			d.Warningf("'%s': %v", tgg.Source, err)
+		}
+		for _, cfg := range cfgs {
+			cfg.SetSource(tgg.Source)
+		}
+		group.Configs = append(group.Configs, cfgs...)
+	}
+	return group
+}
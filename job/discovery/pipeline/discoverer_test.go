@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTargetDiscoverer struct {
+	send [][]*TargetGroup
+}
+
+func (m mockTargetDiscoverer) Run(ctx context.Context, in chan<- []*TargetGroup) {
+	defer close(in)
+	for _, groups := range m.send {
+		select {
+		case <-ctx.Done():
+			return
+		case in <- groups:
+		}
+	}
+}
+
+func TestNewDiscoverer(t *testing.T) {
+	reg := confgroup.Registry{"redis": confgroup.Default{}}
+	targets := mockTargetDiscoverer{}
+
+	tests := map[string]struct {
+		cfg     ComposeConfig
+		targets TargetDiscoverer
+		wantErr bool
+	}{
+		"templates":                   {cfg: ComposeConfig{Templates: []tmpl.Template{{Config: "module: redis"}}}, targets: targets},
+		"rules file":                  {cfg: ComposeConfig{Rules: "../../../examples/config/sd/proc.yml"}, targets: targets},
+		"templates and rules not set": {cfg: ComposeConfig{}, targets: targets, wantErr: true},
+		"target discoverer not set":   {cfg: ComposeConfig{Templates: []tmpl.Template{{Config: "module: redis"}}}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscoverer(reg, test.cfg, test.targets)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscoverer_Run(t *testing.T) {
+	targets := mockTargetDiscoverer{
+		send: [][]*TargetGroup{
+			{
+				{Source: "test:redis", Targets: []interface{}{
+					testTarget{Comm: "redis-server", IP: "127.0.0.1", Port: 6379},
+					testTarget{Comm: "redis-server", IP: "127.0.0.1", Port: 6380},
+				}},
+				{Source: "test:sshd", Targets: []interface{}{testTarget{Comm: "sshd", IP: "0.0.0.0", Port: 22}}},
+			},
+			{nil},
+			{{Source: "test:redis"}},
+		},
+	}
+	d, err := NewDiscoverer(
+		confgroup.Registry{"redis": confgroup.Default{}},
+		ComposeConfig{Templates: []tmpl.Template{
+			{
+				Selector: `{{ eq .Comm "redis-server" }}`,
+				Config:   "module: redis\nname: redis_{{.Port}}\naddress: {{ quote (printf \"%s:%d\" .IP .Port) }}",
+			},
+		}},
+		targets,
+	)
+	require.NoError(t, err)
+
+	in := make(chan []*confgroup.Group)
+	go d.Run(context.Background(), in)
+
+	var groups [][]*confgroup.Group
+	timeout := time.After(time.Second * 5)
+	for done := false; !done; {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				done = true
+				break
+			}
+			groups = append(groups, v)
+		case <-timeout:
+			t.Fatal("timed out waiting for the discoverer to stop")
+		}
+	}
+
+	assert.Equal(t, [][]*confgroup.Group{
+		{
+			{Source: "test:redis", Configs: []confgroup.Config{
+				discoverytest.NewConfig("redis", "redis_6379", confgroup.Config{"address": "127.0.0.1:6379", "__source__": "test:redis"}),
+				discoverytest.NewConfig("redis", "redis_6380", confgroup.Config{"address": "127.0.0.1:6380", "__source__": "test:redis"}),
+			}},
+			{Source: "test:sshd"},
+		},
+		{{Source: "test:redis"}},
+	}, groups)
+}
@@ -0,0 +1,242 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Config is the pipeline rules, every provider has its own rules file.
+	//
+	// A target passes two stages: classify tags the target, then compose renders the job configs
+	// with the templates chosen by the target tags.
+	Config struct {
+		Classify []ClassifyRule `yaml:"classify"`
+		Compose  []ComposeRule  `yaml:"compose"`
+	}
+	// ClassifyRule tags the targets its selector matches (all the targets if not set):
+	// a target gets the rule tags and the match tags if a match expression is true.
+	ClassifyRule struct {
+		Name     string `yaml:"name"`
+		Selector string `yaml:"selector"`
+		Tags     string `yaml:"tags"`
+		Match    []struct {
+			Tags string `yaml:"tags"`
+			// Expr is a Go template expression over the target attributes, e.g. '{{ eq .Comm "redis-server" }}'.
+			Expr string `yaml:"expr"`
+		} `yaml:"match"`
+	}
+	// ComposeRule renders job configs for the targets its selector matches (all the targets if not set).
+	// Every config template with matching (or not set) selector is rendered.
+	ComposeRule struct {
+		Name     string `yaml:"name"`
+		Selector string `yaml:"selector"`
+		// Modules are the modules the rule may render job configs of, any module if not set. See tmpl.Template.
+		Modules []string `yaml:"modules"`
+		Config  []struct {
+			Selector string `yaml:"selector"`
+			// Template renders a YAML job config or a list of job configs, the target is the template data.
+			// The target attributes should be quoted, see tmpl.Template.
+			Template string `yaml:"template"`
+		} `yaml:"config"`
+	}
+)
+
+type (
+	classifyRule struct {
+		name     string
+		selector selector
+		tags     Tags
+		match    []classifyMatch
+	}
+	classifyMatch struct {
+		tags Tags
+		expr *tmpl.Expr
+	}
+	composeRule struct {
+		name     string
+		selector selector
+		config   []composeConfig
+	}
+	composeConfig struct {
+		selector selector
+		composer *tmpl.Composer
+	}
+)
+
+// Pipeline turns discovered targets into job configs: classify → compose.
+type Pipeline struct {
+	classify []classifyRule
+	compose  []composeRule
+}
+
+// Load reads the rules file and creates a Pipeline.
+func Load(path string, reg confgroup.Registry) (*Pipeline, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(bs, &cfg); err != nil {
+		return nil, fmt.Errorf("parse '%s': %v", path, err)
+	}
+	p, err := New(cfg, reg)
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %v", path, err)
+	}
+	return p, nil
+}
+
+// New creates a Pipeline. Configs of modules not in the registry are skipped.
+func New(cfg Config, reg confgroup.Registry) (*Pipeline, error) {
+	if len(cfg.Compose) == 0 {
+		return nil, errors.New("compose rules not set")
+	}
+	p := &Pipeline{}
+
+	for i, r := range cfg.Classify {
+		rule, err := newClassifyRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("classify rule %d ('%s'): %v", i+1, r.Name, err)
+		}
+		p.classify = append(p.classify, rule)
+	}
+	for i, r := range cfg.Compose {
+		rule, err := newComposeRule(r, reg)
+		if err != nil {
+			return nil, fmt.Errorf("compose rule %d ('%s'): %v", i+1, r.Name, err)
+		}
+		p.compose = append(p.compose, rule)
+	}
+	return p, nil
+}
+
+// Compose classifies the target and renders job configs of the templates its tags match.
+// The module defaults are applied. Rendering errors of a template don't stop the other templates.
+func (p *Pipeline) Compose(target interface{}) ([]confgroup.Config, error) {
+	var errs []string
+
+	tags, err := p.Classify(target)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	var cfgs []confgroup.Config
+	for _, rule := range p.compose {
+		if !rule.selector.matches(tags) {
+			continue
+		}
+		for _, c := range rule.config {
+			if !c.selector.matches(tags) {
+				continue
+			}
+			tcfgs, err := c.composer.Compose(target)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("compose rule '%s': %v", rule.name, err))
+			}
+			cfgs = append(cfgs, tcfgs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return cfgs, errors.New(strings.Join(errs, "; "))
+	}
+	return cfgs, nil
+}
+
+// Classify returns the target tags. Expressions evaluation errors don't stop the classification.
+func (p *Pipeline) Classify(target interface{}) (Tags, error) {
+	tags := make(Tags)
+	var errs []string
+
+	for _, rule := range p.classify {
+		if !rule.selector.matches(tags) {
+			continue
+		}
+		for i, m := range rule.match {
+			ok, err := m.expr.Eval(target)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("classify rule '%s': match %d: %v", rule.name, i+1, err))
+				continue
+			}
+			if ok {
+				tags.merge(rule.tags)
+				tags.merge(m.tags)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return tags, errors.New(strings.Join(errs, "; "))
+	}
+	return tags, nil
+}
+
+func newClassifyRule(r ClassifyRule) (classifyRule, error) {
+	rule := classifyRule{name: r.Name}
+	var err error
+
+	if rule.selector, err = parseSelectorOrAny(r.Selector); err != nil {
+		return rule, err
+	}
+	if rule.tags, err = parseTags(r.Tags); err != nil {
+		return rule, err
+	}
+	if len(r.Match) == 0 {
+		return rule, errors.New("match not set")
+	}
+	for i, m := range r.Match {
+		var cm classifyMatch
+		if cm.tags, err = parseTags(m.Tags); err != nil {
+			return rule, fmt.Errorf("match %d: %v", i+1, err)
+		}
+		if len(cm.tags)+len(rule.tags) == 0 {
+			return rule, fmt.Errorf("match %d: tags not set", i+1)
+		}
+		if m.Expr == "" {
+			return rule, fmt.Errorf("match %d: expr not set", i+1)
+		}
+		if cm.expr, err = tmpl.NewExpr(m.Expr); err != nil {
+			return rule, fmt.Errorf("match %d: expr: %v", i+1, err)
+		}
+		rule.match = append(rule.match, cm)
+	}
+	return rule, nil
+}
+
+func newComposeRule(r ComposeRule, reg confgroup.Registry) (composeRule, error) {
+	rule := composeRule{name: r.Name}
+	var err error
+
+	if rule.selector, err = parseSelectorOrAny(r.Selector); err != nil {
+		return rule, err
+	}
+	if len(r.Config) == 0 {
+		return rule, errors.New("config not set")
+	}
+	for i, c := range r.Config {
+		var cc composeConfig
+		if cc.selector, err = parseSelectorOrAny(c.Selector); err != nil {
+			return rule, fmt.Errorf("config %d: %v", i+1, err)
+		}
+		if cc.composer, err = tmpl.New(reg, []tmpl.Template{{Config: c.Template, Modules: r.Modules}}); err != nil {
+			return rule, fmt.Errorf("config %d: %v", i+1, err)
+		}
+		rule.config = append(rule.config, cc)
+	}
+	return rule, nil
+}
+
+func parseSelectorOrAny(line string) (selector, error) {
+	if strings.TrimSpace(line) == "" {
+		return nil, nil
+	}
+	return parseSelector(line)
+}
@@ -0,0 +1,175 @@
+package pipeline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const testRules = `
+classify:
+  - name: applications
+    tags: app
+    match:
+      - tags: redis
+        expr: '{{ eq .Comm "redis-server" }}'
+      - tags: nginx
+        expr: '{{ glob .Comm "nginx*" }}'
+  - name: not local
+    selector: app
+    match:
+      - tags: remote
+        expr: '{{ ne .IP "127.0.0.1" }}'
+compose:
+  - name: applications
+    selector: app
+    modules: [redis, nginx]
+    config:
+      - selector: redis
+        template: |
+          module: redis
+          name: redis_{{.Port}}
+          address: {{ quote (printf "%s:%d" .IP .Port) }}
+      - selector: nginx !remote
+        template: |
+          module: nginx
+          name: local
+          url: {{ quote (printf "http://%s:%d/stub_status" .IP .Port) }}
+`
+
+type testTarget struct {
+	Comm string
+	IP   string
+	Port int
+}
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		rules   string
+		wantErr bool
+	}{
+		"valid rules":                   {rules: testRules},
+		"compose rules not set":         {rules: "classify:\n  - tags: app\n    match:\n      - expr: 'true'\n", wantErr: true},
+		"classify match not set":        {rules: "classify:\n  - tags: app\ncompose:\n  - config:\n      - template: 'module: redis'\n", wantErr: true},
+		"classify match tags not set":   {rules: "classify:\n  - match:\n      - expr: 'true'\ncompose:\n  - config:\n      - template: 'module: redis'\n", wantErr: true},
+		"classify match expr not set":   {rules: "classify:\n  - match:\n      - tags: app\ncompose:\n  - config:\n      - template: 'module: redis'\n", wantErr: true},
+		"classify match invalid expr":   {rules: "classify:\n  - match:\n      - tags: app\n        expr: '{{ .Comm'\ncompose:\n  - config:\n      - template: 'module: redis'\n", wantErr: true},
+		"classify invalid tags":         {rules: "classify:\n  - tags: a$p\n    match:\n      - expr: 'true'\ncompose:\n  - config:\n      - template: 'module: redis'\n", wantErr: true},
+		"compose config not set":        {rules: "compose:\n  - selector: app\n", wantErr: true},
+		"compose config template empty": {rules: "compose:\n  - config:\n      - selector: app\n", wantErr: true},
+		"compose invalid selector":      {rules: "compose:\n  - selector: app|\n    config:\n      - template: 'module: redis'\n", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg Config
+			require.NoError(t, yaml.Unmarshal([]byte(test.rules), &cfg))
+
+			p, err := New(cfg, confgroup.Registry{"redis": {}})
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestPipeline_Compose(t *testing.T) {
+	tests := map[string]struct {
+		target   testTarget
+		wantTags Tags
+		wantCfgs []confgroup.Config
+	}{
+		"redis": {
+			target:   testTarget{Comm: "redis-server", IP: "127.0.0.1", Port: 6379},
+			wantTags: Tags{"app": true, "redis": true},
+			wantCfgs: []confgroup.Config{
+				discoverytest.NewConfig("redis", "redis_6379", confgroup.Config{"address": "127.0.0.1:6379"}),
+			},
+		},
+		"local nginx": {
+			target:   testTarget{Comm: "nginx", IP: "127.0.0.1", Port: 80},
+			wantTags: Tags{"app": true, "nginx": true},
+			wantCfgs: []confgroup.Config{
+				discoverytest.NewConfig("nginx", "local", confgroup.Config{"url": "http://127.0.0.1:80/stub_status"}),
+			},
+		},
+		"remote nginx": {
+			target:   testTarget{Comm: "nginx", IP: "10.0.0.1", Port: 80},
+			wantTags: Tags{"app": true, "nginx": true, "remote": true},
+		},
+		"unknown application": {
+			target:   testTarget{Comm: "sshd", IP: "127.0.0.1", Port: 22},
+			wantTags: Tags{},
+		},
+	}
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(testRules), &cfg))
+	p, err := New(cfg, confgroup.Registry{"redis": {}, "nginx": {}})
+	require.NoError(t, err)
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tags, err := p.Classify(test.target)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantTags, tags)
+
+			cfgs, err := p.Compose(test.target)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantCfgs, cfgs)
+		})
+	}
+}
+
+func TestPipeline_Compose_Modules(t *testing.T) {
+	rules := `
+compose:
+  - modules: [redis]
+    config:
+      - template: |
+          - module: nginx
+          - module: redis
+            name: {{ quote .Comm }}
+`
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(rules), &cfg))
+	p, err := New(cfg, confgroup.Registry{"redis": {}, "nginx": {}})
+	require.NoError(t, err)
+
+	cfgs, err := p.Compose(testTarget{Comm: "redis"})
+	assert.Error(t, err)
+	assert.Equal(t, []confgroup.Config{discoverytest.NewConfig("redis", "redis", nil)}, cfgs)
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-discovery-pipeline")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	valid := filepath.Join(dir, "valid.yml")
+	require.NoError(t, ioutil.WriteFile(valid, []byte(testRules), 0644))
+	invalid := filepath.Join(dir, "invalid.yml")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("compose: [{"), 0644))
+
+	p, err := Load(valid, confgroup.Registry{"redis": {}})
+	assert.NoError(t, err)
+	assert.NotNil(t, p)
+
+	_, err = Load(invalid, confgroup.Registry{"redis": {}})
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(dir, "not-exist.yml"), confgroup.Registry{"redis": {}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tags is a set of target tags.
+type Tags map[string]bool
+
+func parseTags(line string) (Tags, error) {
+	tags := make(Tags)
+	for _, tag := range strings.Fields(line) {
+		if !reTag.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag '%s'", tag)
+		}
+		tags[tag] = true
+	}
+	return tags, nil
+}
+
+func (t Tags) merge(tags Tags) {
+	for tag := range tags {
+		t[tag] = true
+	}
+}
+
+func (t Tags) String() string {
+	tags := make([]string, 0, len(t))
+	for tag := range t {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return "{" + strings.Join(tags, ", ") + "}"
+}
+
+var reTag = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+
+// selector matches tags. The selector is space separated terms, all the terms must match.
+// A term is '|' separated tags, it matches if any of the tags is set, '!' negates a term, '*' matches any tags.
+// E.g. 'app !disabled' or 'redis|memcached'.
+type selector []term
+
+type term struct {
+	tags []string
+	neg  bool
+}
+
+func parseSelector(line string) (selector, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, errors.New("empty selector")
+	}
+
+	var sr selector
+	for _, s := range strings.Fields(line) {
+		if s == "*" {
+			continue
+		}
+		var t term
+		if strings.HasPrefix(s, "!") {
+			t.neg, s = true, s[1:]
+		}
+		for _, tag := range strings.Split(s, "|") {
+			if !reTag.MatchString(tag) {
+				return nil, fmt.Errorf("invalid selector term '%s'", s)
+			}
+			t.tags = append(t.tags, tag)
+		}
+		sr = append(sr, t)
+	}
+	return sr, nil
+}
+
+func (sr selector) matches(tags Tags) bool {
+	for _, t := range sr {
+		if t.matches(tags) == t.neg {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) matches(tags Tags) bool {
+	for _, tag := range t.tags {
+		if tags[tag] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := map[string]struct {
+		line    string
+		wantErr bool
+	}{
+		"single tag":       {line: "app"},
+		"several terms":    {line: "app !disabled redis|memcached"},
+		"any":              {line: "*"},
+		"empty":            {line: " ", wantErr: true},
+		"invalid tag":      {line: "app re$dis", wantErr: true},
+		"empty alternate":  {line: "redis|", wantErr: true},
+		"negation, no tag": {line: "!", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseSelector(test.line)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSelector_matches(t *testing.T) {
+	tests := map[string]struct {
+		line string
+		tags Tags
+		want bool
+	}{
+		"tag is set":                 {line: "app", tags: Tags{"app": true, "redis": true}, want: true},
+		"tag is not set":             {line: "app", tags: Tags{"redis": true}},
+		"all terms match":            {line: "app redis", tags: Tags{"app": true, "redis": true}, want: true},
+		"not all terms match":        {line: "app redis", tags: Tags{"app": true}},
+		"negated tag is not set":     {line: "app !disabled", tags: Tags{"app": true}, want: true},
+		"negated tag is set":         {line: "app !disabled", tags: Tags{"app": true, "disabled": true}},
+		"any alternate is set":       {line: "redis|memcached", tags: Tags{"memcached": true}, want: true},
+		"no alternate is set":        {line: "redis|memcached", tags: Tags{"nginx": true}},
+		"negated alternate is set":   {line: "!redis|memcached", tags: Tags{"memcached": true}},
+		"any, no tags":               {line: "*", tags: Tags{}, want: true},
+		"any and negated tag is set": {line: "* !disabled", tags: Tags{"disabled": true}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr, err := parseSelector(test.line)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, sr.matches(test.tags))
+		})
+	}
+}
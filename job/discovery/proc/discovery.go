@@ -0,0 +1,168 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+)
+
+const provider = "proc"
+
+type Config struct {
+	discovery.ProviderConfig
+	// ComposeConfig creates job configs for the listening sockets, a socket target is Target.
+	pipeline.ComposeConfig
+	// ProcPath is the procfs mount point, default is '/proc'.
+	ProcPath string
+	// RefreshEvery is the listening sockets refresh interval, default is 30 seconds.
+	RefreshEvery time.Duration
+}
+
+// Target is a listening socket, it is the templates data.
+type Target struct {
+	// Protocol is 'tcp', 'tcp6', 'udp' or 'udp6'.
+	Protocol string
+	IP       string
+	Port     int
+	// Address is the host:port to connect to, the loopback address is used for the unspecified IP.
+	Address string
+	// PID is the socket owner process PID, zero if the process is unknown (no permissions to inspect it).
+	PID int
+	// Comm is the process name (/proc/<pid>/comm), e.g. 'redis-server'.
+	Comm    string
+	Cmdline string
+}
+
+// Discovery periodically reads the listening sockets from procfs, every socket is a target group.
+// Groups are sent when sockets appear, change the owner process or disappear.
+type Discovery struct {
+	*logger.Logger
+	procPath     string
+	refreshEvery time.Duration
+	// targets are the sent groups targets by source.
+	targets map[string]Target
+}
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	if cfg.ProcPath == "" {
+		cfg.ProcPath = "/proc"
+	}
+	if cfg.RefreshEvery <= 0 {
+		cfg.RefreshEvery = time.Second * 30
+	}
+	d := &Discovery{
+		Logger:       cfg.Loggers.New("discovery", "proc"),
+		procPath:     cfg.ProcPath,
+		refreshEvery: cfg.RefreshEvery,
+		targets:      make(map[string]Target),
+	}
+	return d, nil
+}
+
+// NewProvider returns a discovery provider of proc discoveries.
+func NewProvider(cfg Config) discovery.Provider {
+	return discovery.NewTargetProvider(cfg.ComposeConfig, func(pcfg discovery.ProviderConfig) (pipeline.TargetDiscoverer, error) {
+		cfg := cfg
+		cfg.ProviderConfig = pcfg
+		return NewDiscovery(cfg)
+	})
+}
+
+func (d Discovery) String() string {
+	return "proc discovery"
+}
+
+func (d *Discovery) Run(ctx context.Context, in chan<- []*pipeline.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	tk := time.NewTicker(d.refreshEvery)
+	defer tk.Stop()
+
+	for {
+		if groups, err := d.refresh(); err != nil {
+			d.Error(err)
+		} else if len(groups) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case in <- groups:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+		}
+	}
+}
+
+// refresh returns groups for the new and changed targets and empty groups for the gone ones.
+func (d *Discovery) refresh() ([]*pipeline.TargetGroup, error) {
+	listeners, err := readListeners(d.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids map[string]int
+	if len(listeners) > 0 {
+		pids = readSocketPIDs(d.procPath)
+	}
+
+	var groups []*pipeline.TargetGroup
+	seen := make(map[string]bool)
+	for _, l := range listeners {
+		target := d.newTarget(l, pids[l.inode])
+		source := fmt.Sprintf("%s:%s:%s", provider, target.Protocol, net.JoinHostPort(target.IP, strconv.Itoa(target.Port)))
+		if seen[source] {
+			// the same address of several processes (SO_REUSEPORT).
+			continue
+		}
+		seen[source] = true
+
+		if prev, ok := d.targets[source]; ok && reflect.DeepEqual(prev, target) {
+			continue
+		}
+		d.targets[source] = target
+		groups = append(groups, &pipeline.TargetGroup{Source: source, Targets: []interface{}{target}})
+	}
+	for source := range d.targets {
+		if !seen[source] {
+			delete(d.targets, source)
+			groups = append(groups, &pipeline.TargetGroup{Source: source})
+		}
+	}
+	return groups, nil
+}
+
+func (d *Discovery) newTarget(l listener, pid int) Target {
+	t := Target{
+		Protocol: l.protocol,
+		IP:       l.ip.String(),
+		Port:     l.port,
+		PID:      pid,
+	}
+	host := t.IP
+	if l.ip.IsUnspecified() {
+		host = "127.0.0.1"
+		if l.ip.To4() == nil {
+			host = "::1"
+		}
+	}
+	t.Address = net.JoinHostPort(host, strconv.Itoa(l.port))
+
+	if pid > 0 {
+		t.Comm = readComm(d.procPath, pid)
+		t.Cmdline = strings.Join(readCmdline(d.procPath, pid), " ")
+	}
+	return t
+}
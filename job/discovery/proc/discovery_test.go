@@ -0,0 +1,260 @@
+package proc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	netHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	// redis listens on 127.0.0.1:6379, nginx on 0.0.0.0:80, a client is connected to redis.
+	tcpRedis  = "   0: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   997        0 1001 1 0 100 0 0 10 0\n"
+	tcpNginx  = "   1: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0 100 0 0 10 0\n"
+	tcpClient = "   2: 0100007F:C350 0100007F:18EB 01 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0 20 4 30 10 -1\n"
+	testTCP   = netHeader + tcpRedis + tcpNginx + tcpClient
+	testTCP6  = netHeader +
+		"   0: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1004 1 0 100 0 0 10 0\n"
+	testUDP = netHeader +
+		"   0: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 1005 2 0 0\n"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: Config{ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: redis"}}}},
+		},
+		"valid config, rules file": {
+			cfg: Config{ComposeConfig: pipeline.ComposeConfig{Rules: "../../../examples/config/sd/proc.yml"}},
+		},
+		"invalid config, rules file doesnt exist": {
+			cfg:     Config{ComposeConfig: pipeline.ComposeConfig{Rules: "not-exist.yml"}},
+			wantErr: true,
+		},
+		"invalid config, templates not set": {
+			cfg:     Config{},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewProvider(test.cfg)(discovery.ProviderConfig{
+				Registry: confgroup.Registry{"redis": confgroup.Default{}},
+			})
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestDiscovery_refresh(t *testing.T) {
+	procPath, cleanup := prepareProcFS(t)
+	defer cleanup()
+
+	d, err := NewDiscovery(Config{ProcPath: procPath})
+	require.NoError(t, err)
+
+	groups, err := d.refresh()
+	require.NoError(t, err)
+	assert.Equal(t, []*pipeline.TargetGroup{
+		{
+			Source: "proc:tcp:127.0.0.1:6379",
+			Targets: []interface{}{Target{
+				Protocol: "tcp",
+				IP:       "127.0.0.1",
+				Port:     6379,
+				Address:  "127.0.0.1:6379",
+				PID:      100,
+				Comm:     "redis-server",
+				Cmdline:  "/usr/bin/redis-server 127.0.0.1:6379",
+			}},
+		},
+		{
+			Source: "proc:tcp:0.0.0.0:80",
+			Targets: []interface{}{Target{
+				Protocol: "tcp",
+				IP:       "0.0.0.0",
+				Port:     80,
+				Address:  "127.0.0.1:80",
+				PID:      200,
+				Comm:     "nginx",
+				Cmdline:  "nginx: master process /usr/sbin/nginx",
+			}},
+		},
+		{
+			Source: "proc:tcp6:[::]:80",
+			Targets: []interface{}{Target{
+				Protocol: "tcp6",
+				IP:       "::",
+				Port:     80,
+				Address:  "[::1]:80",
+				PID:      200,
+				Comm:     "nginx",
+				Cmdline:  "nginx: master process /usr/sbin/nginx",
+			}},
+		},
+		{
+			Source: "proc:udp:127.0.0.53:53",
+			Targets: []interface{}{Target{
+				Protocol: "udp",
+				IP:       "127.0.0.53",
+				Port:     53,
+				Address:  "127.0.0.53:53",
+			}},
+		},
+	}, groups)
+
+	// nothing changed.
+	groups, err = d.refresh()
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	// redis is stopped.
+	writeFile(t, filepath.Join(procPath, "net", "tcp"), netHeader+tcpNginx+tcpClient)
+	groups, err = d.refresh()
+	require.NoError(t, err)
+	assert.Equal(t, []*pipeline.TargetGroup{{Source: "proc:tcp:127.0.0.1:6379"}}, groups)
+}
+
+func TestNewProvider_Run(t *testing.T) {
+	tests := map[string]struct {
+		compose    pipeline.ComposeConfig
+		wantGroups []*confgroup.Group
+	}{
+		"templates": {
+			compose: pipeline.ComposeConfig{
+				Templates: []tmpl.Template{
+					{
+						Selector: `{{ and (eq .Protocol "tcp") (eq .Comm "redis-server") }}`,
+						Config:   "module: redis\nname: local\naddress: {{ quote .Address }}",
+					},
+					{
+						Selector: `{{ and (eq .Comm "nginx") (eq .Port 80) }}`,
+						Config:   "module: nginx\nname: local_{{.Protocol}}\nurl: {{ quote (printf \"http://%s/stub_status\" .Address) }}",
+					},
+				},
+			},
+			wantGroups: []*confgroup.Group{
+				{
+					Source:  "proc:tcp:127.0.0.1:6379",
+					Configs: []confgroup.Config{discoverytest.NewConfig("redis", "local", confgroup.Config{"address": "127.0.0.1:6379", "__source__": "proc:tcp:127.0.0.1:6379"})},
+				},
+				{
+					Source:  "proc:tcp:0.0.0.0:80",
+					Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "local_tcp", confgroup.Config{"url": "http://127.0.0.1:80/stub_status", "__source__": "proc:tcp:0.0.0.0:80"})},
+				},
+				{
+					Source:  "proc:tcp6:[::]:80",
+					Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "local_tcp6", confgroup.Config{"url": "http://[::1]:80/stub_status", "__source__": "proc:tcp6:[::]:80"})},
+				},
+				{Source: "proc:udp:127.0.0.53:53"},
+			},
+		},
+		"rules file": {
+			compose: pipeline.ComposeConfig{Rules: "../../../examples/config/sd/proc.yml"},
+			wantGroups: []*confgroup.Group{
+				{
+					Source:  "proc:tcp:127.0.0.1:6379",
+					Configs: []confgroup.Config{discoverytest.NewConfig("redis", "local_6379", confgroup.Config{"address": "redis://127.0.0.1:6379", "__source__": "proc:tcp:127.0.0.1:6379"})},
+				},
+				{
+					Source:  "proc:tcp:0.0.0.0:80",
+					Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "local", confgroup.Config{"url": "http://127.0.0.1:80/stub_status", "__source__": "proc:tcp:0.0.0.0:80"})},
+				},
+				{
+					Source:  "proc:tcp6:[::]:80",
+					Configs: []confgroup.Config{discoverytest.NewConfig("nginx", "local", confgroup.Config{"url": "http://[::1]:80/stub_status", "__source__": "proc:tcp6:[::]:80"})},
+				},
+				{Source: "proc:udp:127.0.0.53:53"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			procPath, cleanup := prepareProcFS(t)
+			defer cleanup()
+
+			d, err := NewProvider(Config{ProcPath: procPath, ComposeConfig: test.compose})(discovery.ProviderConfig{
+				Registry: confgroup.Registry{"redis": confgroup.Default{}, "nginx": confgroup.Default{}},
+			})
+			require.NoError(t, err)
+
+			in := make(chan []*confgroup.Group)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go d.Run(ctx, in)
+
+			assert.Equal(t, test.wantGroups, discoverytest.ReceiveGroups(t, in))
+		})
+	}
+}
+
+func TestDiscovery_refresh_NoNetFiles(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-discovery-proc")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	d, err := NewDiscovery(Config{ProcPath: dir})
+	require.NoError(t, err)
+
+	_, err = d.refresh()
+	assert.Error(t, err)
+}
+
+// prepareProcFS creates a fake procfs: the net files and the redis (pid 100) and nginx (pid 200) processes.
+// The resolver socket (udp) owner process is unknown.
+func prepareProcFS(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-discovery-proc")
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(dir, "net", "tcp"), testTCP)
+	writeFile(t, filepath.Join(dir, "net", "tcp6"), testTCP6)
+	writeFile(t, filepath.Join(dir, "net", "udp"), testUDP)
+
+	processes := []struct {
+		pid     string
+		comm    string
+		cmdline string
+		inodes  []string
+	}{
+		{pid: "100", comm: "redis-server", cmdline: "/usr/bin/redis-server\x00127.0.0.1:6379\x00", inodes: []string{"1001"}},
+		{pid: "200", comm: "nginx", cmdline: "nginx: master process /usr/sbin/nginx\x00", inodes: []string{"1002", "1004"}},
+	}
+	for _, p := range processes {
+		writeFile(t, filepath.Join(dir, p.pid, "comm"), p.comm+"\n")
+		writeFile(t, filepath.Join(dir, p.pid, "cmdline"), p.cmdline)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, p.pid, "fd"), 0755))
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(dir, p.pid, "fd", "0")))
+		for i, inode := range p.inodes {
+			require.NoError(t, os.Symlink("socket:["+inode+"]", filepath.Join(dir, p.pid, "fd", string(rune('3'+i)))))
+		}
+	}
+
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
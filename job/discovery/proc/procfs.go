@@ -0,0 +1,156 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	tcpListen   = "0A"
+	udpUnconned = "07"
+)
+
+// listener is a listening socket parsed from /proc/net/{tcp,tcp6,udp,udp6}.
+type listener struct {
+	protocol string
+	ip       net.IP
+	port     int
+	inode    string
+}
+
+// readListeners returns the listening TCP sockets and the unconnected UDP sockets.
+func readListeners(procPath string) ([]listener, error) {
+	var listeners []listener
+	var found bool
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		ls, err := readNetFile(filepath.Join(procPath, "net", protocol), protocol)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// no IPv6 support.
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		listeners = append(listeners, ls...)
+	}
+	if !found {
+		return nil, fmt.Errorf("'%s' net files not found", procPath)
+	}
+	return listeners, nil
+}
+
+func readNetFile(path, protocol string) ([]listener, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	state := tcpListen
+	if strings.HasPrefix(protocol, "udp") {
+		state = udpUnconned
+	}
+
+	var listeners []listener
+	sc := bufio.NewScanner(f)
+	// skip the header.
+	sc.Scan()
+	for sc.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		parts := strings.Fields(sc.Text())
+		if len(parts) < 10 || parts[3] != state {
+			continue
+		}
+		ip, port, err := parseAddress(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse '%s': %v", path, err)
+		}
+		listeners = append(listeners, listener{protocol: protocol, ip: ip, port: port, inode: parts[9]})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read '%s': %v", path, err)
+	}
+	return listeners, nil
+}
+
+// parseAddress parses the hex encoded address, e.g. '0100007F:1F90' is 127.0.0.1:8080.
+// The IP is a sequence of 32-bit words in the host (little endian) byte order.
+func parseAddress(s string) (net.IP, int, error) {
+	i := strings.IndexByte(s, ':')
+	if i == -1 {
+		return nil, 0, fmt.Errorf("invalid address '%s'", s)
+	}
+	bs, err := hex.DecodeString(s[:i])
+	if err != nil || (len(bs) != net.IPv4len && len(bs) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address '%s'", s)
+	}
+	for w := 0; w < len(bs); w += 4 {
+		bs[w], bs[w+1], bs[w+2], bs[w+3] = bs[w+3], bs[w+2], bs[w+1], bs[w]
+	}
+	port, err := strconv.ParseInt(s[i+1:], 16, 32)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid address '%s'", s)
+	}
+	return net.IP(bs), int(port), nil
+}
+
+// readSocketPIDs returns the socket inode owner processes PIDs.
+// Processes the plugin has no permissions to inspect are skipped.
+func readSocketPIDs(procPath string) map[string]int {
+	pids := make(map[string]int)
+	dirs, err := ioutil.ReadDir(procPath)
+	if err != nil {
+		return pids
+	}
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil || !dir.IsDir() {
+			continue
+		}
+		fdDir := filepath.Join(procPath, dir.Name(), "fd")
+		fds, err := ioutil.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			pids[inode] = pid
+		}
+	}
+	return pids
+}
+
+// readComm returns the process name.
+func readComm(procPath string, pid int) string {
+	bs, err := ioutil.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(bs))
+}
+
+// readCmdline returns the process command line arguments.
+func readCmdline(procPath string, pid int) []string {
+	bs, err := ioutil.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil
+	}
+	bs = bytes.TrimRight(bs, "\x00")
+	if len(bs) == 0 {
+		return nil
+	}
+	return strings.Split(string(bs), "\x00")
+}
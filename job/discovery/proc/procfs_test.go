@@ -0,0 +1,40 @@
+package proc
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := map[string]struct {
+		address  string
+		wantIP   net.IP
+		wantPort int
+		wantErr  bool
+	}{
+		"ipv4":             {address: "0100007F:18EB", wantIP: net.IPv4(127, 0, 0, 1).To4(), wantPort: 6379},
+		"ipv4 unspecified": {address: "00000000:0050", wantIP: net.IPv4zero.To4(), wantPort: 80},
+		"ipv6 loopback":    {address: "00000000000000000000000001000000:1F90", wantIP: net.IPv6loopback, wantPort: 8080},
+		"ipv6 mapped ipv4": {address: "0000000000000000FFFF00000100007F:0035", wantIP: net.IPv4(127, 0, 0, 1), wantPort: 53},
+		"no port":          {address: "0100007F", wantErr: true},
+		"invalid ip":       {address: "0100007:0050", wantErr: true},
+		"invalid port":     {address: "0100007F:PORT", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ip, port, err := parseAddress(test.address)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, test.wantIP.Equal(ip), "want %s, got %s", test.wantIP, ip)
+				assert.Equal(t, test.wantPort, port)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+)
+
+// NewProvider returns a Provider of a config discoverer. create is called with the discovery manager registry
+// and loggers, it should set them to its own copy of the discoverer config, a Provider can be called concurrently.
+func NewProvider(create func(ProviderConfig) (Discoverer, error)) Provider {
+	return func(pcfg ProviderConfig) (Discoverer, error) {
+		return create(pcfg)
+	}
+}
+
+// NewTargetProvider returns a Provider of a target discoverer, the discovered targets job configs are composed
+// in the pipeline stage (see pipeline.Discoverer). create is called with the discovery manager registry and loggers
+// the same way as in NewProvider.
+func NewTargetProvider(compose pipeline.ComposeConfig, create func(ProviderConfig) (pipeline.TargetDiscoverer, error)) Provider {
+	return func(pcfg ProviderConfig) (Discoverer, error) {
+		targets, err := create(pcfg)
+		if err != nil {
+			return nil, err
+		}
+		d, err := pipeline.NewDiscoverer(pcfg.Registry, compose, targets)
+		if err != nil {
+			return nil, err
+		}
+		d.Logger = pcfg.Loggers.New("discovery", fmt.Sprintf("%v pipeline", targets))
+		return d, nil
+	}
+}
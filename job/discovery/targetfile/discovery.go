@@ -0,0 +1,174 @@
+package targetfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/file"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+
+	"gopkg.in/yaml.v2"
+)
+
+const provider = "targetfile"
+
+type Config struct {
+	discovery.ProviderConfig
+	// ComposeConfig creates job configs for the files targets, a target is a file list item (map[string]interface{}).
+	pipeline.ComposeConfig
+	// Files are the target files patterns, the syntax is the same as in file.Match.
+	// A target file is a YAML (or JSON) list of targets, e.g. '- address: 127.0.0.1:6379'.
+	Files []string
+	// Exclude are the files and dirs exclusion patterns. See file.IsExcluded.
+	Exclude []string
+	// RefreshEvery is the files refresh interval, default is 10 seconds.
+	RefreshEvery time.Duration
+}
+
+func validateConfig(cfg Config) error {
+	if len(cfg.Files) == 0 {
+		return errors.New("files not set")
+	}
+	for _, pattern := range cfg.Files {
+		if _, err := file.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad files pattern '%s': %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Discovery periodically reads the target files, every file is a target group.
+// Groups are sent when files appear, change or disappear.
+type Discovery struct {
+	*logger.Logger
+	files        []string
+	exclude      []string
+	refreshEvery time.Duration
+	// modTimes are the sent groups files modification time by path.
+	modTimes map[string]time.Time
+}
+
+func NewDiscovery(cfg Config) (*Discovery, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("targetfile discovery config validation: %v", err)
+	}
+	if cfg.RefreshEvery <= 0 {
+		cfg.RefreshEvery = time.Second * 10
+	}
+
+	d := &Discovery{
+		Logger:       cfg.Loggers.New("discovery", "targetfile"),
+		files:        cfg.Files,
+		exclude:      cfg.Exclude,
+		refreshEvery: cfg.RefreshEvery,
+		modTimes:     make(map[string]time.Time),
+	}
+	return d, nil
+}
+
+// NewProvider returns a discovery provider of targetfile discoveries.
+func NewProvider(cfg Config) discovery.Provider {
+	return discovery.NewTargetProvider(cfg.ComposeConfig, func(pcfg discovery.ProviderConfig) (pipeline.TargetDiscoverer, error) {
+		cfg := cfg
+		cfg.ProviderConfig = pcfg
+		return NewDiscovery(cfg)
+	})
+}
+
+func (d Discovery) String() string {
+	return "targetfile discovery"
+}
+
+func (d *Discovery) Run(ctx context.Context, in chan<- []*pipeline.TargetGroup) {
+	d.Info("instance is started")
+	defer func() { d.Info("instance is stopped") }()
+
+	tk := time.NewTicker(d.refreshEvery)
+	defer tk.Stop()
+
+	for {
+		if groups := d.refresh(); len(groups) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case in <- groups:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+		}
+	}
+}
+
+// refresh returns groups for the new and changed files and empty groups for the gone ones.
+// A file that can't be read is retried on the next refresh, its previously sent group is kept.
+func (d *Discovery) refresh() []*pipeline.TargetGroup {
+	var groups []*pipeline.TargetGroup
+	seen := make(map[string]bool)
+
+	for _, pattern := range d.files {
+		matches, err := file.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if seen[path] || file.IsExcluded(d.exclude, path) {
+				continue
+			}
+			fi, err := os.Stat(path)
+			if err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+			seen[path] = true
+
+			if modTime, ok := d.modTimes[path]; ok && modTime.Equal(fi.ModTime()) {
+				continue
+			}
+			targets, err := readTargets(path)
+			if err != nil {
+				d.Warningf("read '%s': %v", path, err)
+				continue
+			}
+			d.modTimes[path] = fi.ModTime()
+			groups = append(groups, &pipeline.TargetGroup{Source: source(path), Targets: targets})
+		}
+	}
+	for path := range d.modTimes {
+		if !seen[path] {
+			delete(d.modTimes, path)
+			groups = append(groups, &pipeline.TargetGroup{Source: source(path)})
+		}
+	}
+	return groups
+}
+
+func readTargets(path string) ([]interface{}, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	if err := yaml.Unmarshal(bs, &items); err != nil {
+		return nil, err
+	}
+	var targets []interface{}
+	for _, item := range items {
+		if len(item) != 0 {
+			targets = append(targets, item)
+		}
+	}
+	return targets, nil
+}
+
+func source(path string) string {
+	return provider + ":" + path
+}
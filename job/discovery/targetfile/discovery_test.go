@@ -0,0 +1,213 @@
+package targetfile
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/discoverytest"
+	"github.com/netdata/go-orchestrator/job/discovery/pipeline"
+	"github.com/netdata/go-orchestrator/job/discovery/tmpl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiscovery(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: Config{Files: []string{"/etc/netdata/sd/targets/**/*.yml"}},
+		},
+		"invalid config, files not set": {
+			cfg:     Config{},
+			wantErr: true,
+		},
+		"invalid config, bad files pattern": {
+			cfg:     Config{Files: []string{"/etc/netdata/sd/[.yml"}},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDiscovery(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid config": {
+			cfg: Config{
+				Files:         []string{"/etc/netdata/sd/targets/*.yml"},
+				ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: redis"}}},
+			},
+		},
+		"invalid config, templates not set": {
+			cfg:     Config{Files: []string{"/etc/netdata/sd/targets/*.yml"}},
+			wantErr: true,
+		},
+		"invalid config, files not set": {
+			cfg:     Config{ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: redis"}}}},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewProvider(test.cfg)(discovery.ProviderConfig{
+				Registry: confgroup.Registry{"redis": confgroup.Default{}},
+			})
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, d)
+			}
+		})
+	}
+}
+
+func TestNewProvider_ConcurrentCalls(t *testing.T) {
+	provider := NewProvider(Config{
+		Files:         []string{"/etc/netdata/sd/targets/*.yml"},
+		ComposeConfig: pipeline.ComposeConfig{Templates: []tmpl.Template{{Config: "module: redis"}}},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d, err := provider(discovery.ProviderConfig{Registry: confgroup.Registry{"redis": confgroup.Default{}}})
+			assert.NoError(t, err)
+			assert.NotNil(t, d)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiscovery_refresh(t *testing.T) {
+	dir, cleanup := prepareDir(t)
+	defer cleanup()
+
+	redis := filepath.Join(dir, "redis.yml")
+	nginx := filepath.Join(dir, "web", "nginx.json")
+	writeFile(t, redis, "- address: 127.0.0.1:6379\n  app: redis\n- address: 127.0.0.1:6380\n  app: redis\n")
+	writeFile(t, nginx, `[{"url": "http://127.0.0.1/stub_status", "app": "nginx"}]`)
+	writeFile(t, filepath.Join(dir, "redis.yml.bak"), "- address: 127.0.0.1:6381\n")
+
+	d, err := NewDiscovery(Config{
+		Files:   []string{filepath.Join(dir, "**", "*")},
+		Exclude: []string{"*.bak"},
+	})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []*pipeline.TargetGroup{
+		{
+			Source: "targetfile:" + redis,
+			Targets: []interface{}{
+				map[string]interface{}{"address": "127.0.0.1:6379", "app": "redis"},
+				map[string]interface{}{"address": "127.0.0.1:6380", "app": "redis"},
+			},
+		},
+		{
+			Source:  "targetfile:" + nginx,
+			Targets: []interface{}{map[string]interface{}{"url": "http://127.0.0.1/stub_status", "app": "nginx"}},
+		},
+	}, d.refresh())
+
+	// nothing changed.
+	assert.Empty(t, d.refresh())
+
+	// a redis target is removed, the file is not valid for a while.
+	writeFile(t, redis, "- address: [")
+	touch(t, redis, time.Now().Add(time.Second))
+	assert.Empty(t, d.refresh())
+
+	writeFile(t, redis, "- address: 127.0.0.1:6379\n  app: redis\n")
+	touch(t, redis, time.Now().Add(time.Second*2))
+	assert.Equal(t, []*pipeline.TargetGroup{
+		{
+			Source:  "targetfile:" + redis,
+			Targets: []interface{}{map[string]interface{}{"address": "127.0.0.1:6379", "app": "redis"}},
+		},
+	}, d.refresh())
+
+	// nginx file is removed.
+	require.NoError(t, os.Remove(nginx))
+	assert.Equal(t, []*pipeline.TargetGroup{{Source: "targetfile:" + nginx}}, d.refresh())
+}
+
+func TestNewProvider_Run(t *testing.T) {
+	dir, cleanup := prepareDir(t)
+	defer cleanup()
+
+	redis := filepath.Join(dir, "redis.yml")
+	writeFile(t, redis, "- address: 127.0.0.1:6379\n  app: redis\n- address: 127.0.0.1:80\n  app: nginx\n")
+
+	d, err := NewProvider(Config{
+		Files: []string{filepath.Join(dir, "*.yml")},
+		ComposeConfig: pipeline.ComposeConfig{
+			Templates: []tmpl.Template{
+				{
+					Selector: `{{ eq .app "redis" }}`,
+					Config:   "module: redis\nname: local\naddress: {{ quote (printf \"redis://%s\" .address) }}",
+				},
+			},
+		},
+	})(discovery.ProviderConfig{Registry: confgroup.Registry{"redis": confgroup.Default{}}})
+	require.NoError(t, err)
+
+	in := make(chan []*confgroup.Group)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx, in)
+
+	assert.Equal(t, []*confgroup.Group{
+		{
+			Source: "targetfile:" + redis,
+			Configs: []confgroup.Config{
+				discoverytest.NewConfig("redis", "local", confgroup.Config{
+					"address":    "redis://127.0.0.1:6379",
+					"__source__": "targetfile:" + redis,
+				}),
+			},
+		},
+	}, discoverytest.ReceiveGroups(t, in))
+}
+
+func prepareDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-discovery-targetfile")
+	require.NoError(t, err)
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func touch(t *testing.T, path string, mtime time.Time) {
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
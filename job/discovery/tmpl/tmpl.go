@@ -0,0 +1,192 @@
+package tmpl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Template creates job configs for discovered targets.
+type Template struct {
+	// Selector is a Go template, the target matches if it renders to "true". Empty selector matches any target.
+	Selector string `yaml:"selector"`
+	// Config is a Go template that renders a YAML job config or a list of job configs, the module must be set.
+	// The target attributes are not trusted (e.g. container labels), they should be quoted with 'quote':
+	// a value with a newline would add config keys or whole jobs, e.g. 'address: {{ quote .Address }}'.
+	Config string `yaml:"config"`
+	// Modules are the modules the template may render job configs of, configs of other modules are skipped.
+	// Any module is allowed if not set.
+	Modules []string `yaml:"modules"`
+}
+
+type compiled struct {
+	selector *Expr
+	config   *template.Template
+	modules  map[string]bool
+}
+
+// Expr is a Go template boolean expression, e.g. '{{ glob .Image "nginx*" }}'.
+type Expr struct {
+	t *template.Template
+}
+
+// NewExpr parses the expression.
+func NewExpr(text string) (*Expr, error) {
+	t, err := parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{t: t}, nil
+}
+
+// Eval returns whether the expression renders to "true" for the data.
+func (e *Expr) Eval(data interface{}) (bool, error) {
+	var buf bytes.Buffer
+	if err := e.t.Execute(&buf, data); err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(buf.String()) == "true", nil
+}
+
+// Composer renders the templates job configs for a target.
+type Composer struct {
+	reg       confgroup.Registry
+	templates []compiled
+}
+
+// New creates a Composer. Configs of modules not in the registry are skipped.
+func New(reg confgroup.Registry, templates []Template) (*Composer, error) {
+	if len(templates) == 0 {
+		return nil, errors.New("templates not set")
+	}
+	c := &Composer{reg: reg}
+	for i, t := range templates {
+		if t.Config == "" {
+			return nil, fmt.Errorf("template %d: config not set", i+1)
+		}
+		var ct compiled
+		var err error
+		if t.Selector != "" {
+			if ct.selector, err = NewExpr(t.Selector); err != nil {
+				return nil, fmt.Errorf("template %d: selector: %v", i+1, err)
+			}
+		}
+		if ct.config, err = parse(t.Config); err != nil {
+			return nil, fmt.Errorf("template %d: config: %v", i+1, err)
+		}
+		if len(t.Modules) != 0 {
+			ct.modules = make(map[string]bool)
+			for _, name := range t.Modules {
+				ct.modules[name] = true
+			}
+		}
+		c.templates = append(c.templates, ct)
+	}
+	return c, nil
+}
+
+// Compose renders job configs of all the templates the target matches, the module defaults are applied.
+// Rendering errors of a template don't stop the other templates, they are joined in the returned error.
+func (c *Composer) Compose(target interface{}) ([]confgroup.Config, error) {
+	var cfgs []confgroup.Config
+	var errs []string
+	for i, t := range c.templates {
+		ok, err := eval(t.selector, target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("template %d: selector: %v", i+1, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		tcfgs, err := render(t.config, target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("template %d: config: %v", i+1, err))
+			continue
+		}
+		for _, cfg := range tcfgs {
+			if t.modules != nil && !t.modules[cfg.Module()] {
+				errs = append(errs, fmt.Sprintf("template %d: module '%s' is not allowed", i+1, cfg.Module()))
+				continue
+			}
+			if def, ok := c.reg.Lookup(cfg.Module()); ok && cfg.Module() != "" {
+				cfg.Apply(def)
+				cfgs = append(cfgs, cfg)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return cfgs, errors.New(strings.Join(errs, "; "))
+	}
+	return cfgs, nil
+}
+
+func eval(selector *Expr, target interface{}) (bool, error) {
+	if selector == nil {
+		return true, nil
+	}
+	return selector.Eval(target)
+}
+
+func render(config *template.Template, target interface{}) ([]confgroup.Config, error) {
+	var buf bytes.Buffer
+	if err := config.Execute(&buf, target); err != nil {
+		return nil, err
+	}
+	bs := bytes.TrimSpace(buf.Bytes())
+	if len(bs) == 0 {
+		return nil, nil
+	}
+
+	if bs[0] == '-' {
+		var cfgs []confgroup.Config
+		if err := yaml.Unmarshal(bs, &cfgs); err != nil {
+			return nil, err
+		}
+		var i int
+		for _, cfg := range cfgs {
+			if len(cfg) > 0 {
+				cfgs[i] = cfg
+				i++
+			}
+		}
+		return cfgs[:i], nil
+	}
+	var cfg confgroup.Config
+	if err := yaml.Unmarshal(bs, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	return []confgroup.Config{cfg}, nil
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("").Option("missingkey=zero").Funcs(funcMap).Parse(text)
+}
+
+var funcMap = template.FuncMap{
+	// glob reports whether the value matches any of the shell patterns.
+	"glob": func(value string, patterns ...string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, value); ok {
+				return true
+			}
+		}
+		return false
+	},
+	// quote returns the value as a YAML double-quoted scalar, e.g. 'url: {{ quote (printf "http://%s/" .Address) }}'.
+	"quote": func(value interface{}) string {
+		bs, _ := json.Marshal(fmt.Sprint(value))
+		return string(bs)
+	},
+}
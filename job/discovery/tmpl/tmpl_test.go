@@ -0,0 +1,160 @@
+package tmpl
+
+import (
+	"testing"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTarget struct {
+	Name  string
+	Image string
+	Ports []int
+}
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		templates []Template
+		wantErr   bool
+	}{
+		"valid templates":         {templates: []Template{{Selector: `{{ glob .Image "nginx*" }}`, Config: "module: nginx"}}},
+		"templates not set":       {wantErr: true},
+		"config not set":          {templates: []Template{{Selector: "true"}}, wantErr: true},
+		"invalid selector syntax": {templates: []Template{{Selector: "{{ .Image", Config: "module: nginx"}}, wantErr: true},
+		"invalid config syntax":   {templates: []Template{{Config: "module: {{ .Name"}}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(confgroup.Registry{"nginx": {}}, test.templates)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, c)
+			}
+		})
+	}
+}
+
+func TestComposer_Compose(t *testing.T) {
+	reg := confgroup.Registry{
+		"nginx": confgroup.Default{UpdateEvery: 5},
+		"redis": confgroup.Default{},
+	}
+	tests := map[string]struct {
+		templates []Template
+		target    testTarget
+		wantCfgs  []confgroup.Config
+		wantErr   bool
+	}{
+		"single config": {
+			templates: []Template{{Selector: `{{ glob .Image "nginx*" }}`, Config: "module: nginx\nname: {{.Name}}"}},
+			target:    testTarget{Name: "web", Image: "nginx:latest"},
+			wantCfgs: []confgroup.Config{
+				{
+					"module":              "nginx",
+					"name":                "web",
+					"update_every":        5,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+				},
+			},
+		},
+		"list of configs": {
+			templates: []Template{{Config: "{{range .Ports}}\n- module: redis\n  name: {{$.Name}}_{{.}}\n{{end}}"}},
+			target:    testTarget{Name: "cache", Image: "redis", Ports: []int{6379, 6380}},
+			wantCfgs: []confgroup.Config{
+				{
+					"module":              "redis",
+					"name":                "cache_6379",
+					"update_every":        module.UpdateEvery,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+				},
+				{
+					"module":              "redis",
+					"name":                "cache_6380",
+					"update_every":        module.UpdateEvery,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+				},
+			},
+		},
+		"selector doesnt match": {
+			templates: []Template{{Selector: `{{ glob .Image "nginx*" }}`, Config: "module: nginx"}},
+			target:    testTarget{Name: "cache", Image: "redis"},
+		},
+		"empty rendered config": {
+			templates: []Template{{Config: "{{ if .Ports }}module: redis{{ end }}"}},
+			target:    testTarget{Name: "cache", Image: "redis"},
+		},
+		"module not in registry": {
+			templates: []Template{{Config: "module: mysql"}},
+			target:    testTarget{Name: "db", Image: "mysql"},
+		},
+		"quoted attribute": {
+			templates: []Template{{Config: "module: redis\nname: cache\naddress: {{ quote .Name }}"}},
+			target:    testTarget{Name: "127.0.0.1\nmodule: exec\ncommand: 'rm -rf /'", Image: "redis"},
+			wantCfgs: []confgroup.Config{
+				{
+					"module":              "redis",
+					"name":                "cache",
+					"address":             "127.0.0.1\nmodule: exec\ncommand: 'rm -rf /'",
+					"update_every":        module.UpdateEvery,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+				},
+			},
+		},
+		"module not allowed": {
+			templates: []Template{{Config: "- module: nginx\n- module: redis\n  name: {{ quote .Name }}", Modules: []string{"redis"}}},
+			target:    testTarget{Name: "cache", Image: "redis"},
+			wantCfgs: []confgroup.Config{
+				{
+					"module":              "redis",
+					"name":                "cache",
+					"update_every":        module.UpdateEvery,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+				},
+			},
+			wantErr: true,
+		},
+		"invalid rendered config": {
+			templates: []Template{{Config: "module: [nginx"}, {Config: "module: redis\nname: {{.Name}}"}},
+			target:    testTarget{Name: "cache", Image: "redis"},
+			wantCfgs: []confgroup.Config{
+				{
+					"module":              "redis",
+					"name":                "cache",
+					"update_every":        module.UpdateEvery,
+					"autodetection_retry": module.AutoDetectionRetry,
+					"priority":            module.Priority,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(reg, test.templates)
+			require.NoError(t, err)
+
+			cfgs, err := c.Compose(test.target)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantCfgs, cfgs)
+		})
+	}
+}
@@ -54,21 +54,43 @@ func (m *Manager) Start(job jobpkg.Job) {
 }
 
 // Stop stops a job and removes it from the job queue.
+// The job is stopped outside the lock, it allows to stop several jobs in parallel.
 func (m *Manager) Stop(fullName string) {
 	m.mux.Lock()
-	defer m.mux.Unlock()
+	job := m.queue.remove(fullName)
+	m.mux.Unlock()
 
-	if job := m.queue.remove(fullName); job != nil {
+	if job != nil {
 		job.Stop()
 	}
 }
 
-// Cleanup stops all jobs in the queue.
-func (m *Manager) Cleanup() {
-	for _, v := range m.queue {
-		v.Stop()
+// Lookup returns the job from the job queue.
+func (m *Manager) Lookup(fullName string) (jobpkg.Job, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for _, job := range m.queue {
+		if job.FullName() == fullName {
+			return job, true
+		}
 	}
+	return nil, false
+}
+
+// Cleanup stops all jobs in the queue in parallel. It blocks until all jobs are stopped.
+func (m *Manager) Cleanup() {
+	m.mux.Lock()
+	jobs := append(queue(nil), m.queue...)
 	m.queue = m.queue[:0]
+	m.mux.Unlock()
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job jobpkg.Job) { defer wg.Done(); job.Stop() }(job)
+	}
+	wg.Wait()
 }
 
 func (m *Manager) notify(clock int) {
@@ -1,6 +1,15 @@
 package run
 
-import "testing"
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	jobpkg "github.com/netdata/go-orchestrator/job"
+
+	"github.com/stretchr/testify/assert"
+)
 
 // TODO: tech dept
 func TestNewManager(t *testing.T) {
@@ -11,3 +20,46 @@ func TestNewManager(t *testing.T) {
 func TestManager_Run(t *testing.T) {
 
 }
+
+func TestManager_Stop(t *testing.T) {
+	mgr := NewManager()
+	var stopped []string
+	for i := 0; i < 3; i++ {
+		name := "job" + strconv.Itoa(i)
+		mgr.Start(jobpkg.MockJob{
+			FullNameFunc: func() string { return name },
+			StopFunc:     func() { stopped = append(stopped, name) },
+		})
+	}
+
+	mgr.Stop("job1")
+	mgr.Stop("job1")
+
+	assert.Equal(t, []string{"job1"}, stopped)
+	assert.Len(t, mgr.queue, 2)
+}
+
+func TestManager_Cleanup(t *testing.T) {
+	mgr := NewManager()
+	var mux sync.Mutex
+	var stopped int
+	for i := 0; i < 5; i++ {
+		name := "job" + strconv.Itoa(i)
+		mgr.Start(jobpkg.MockJob{
+			FullNameFunc: func() string { return name },
+			StopFunc: func() {
+				time.Sleep(time.Millisecond * 200)
+				mux.Lock()
+				stopped++
+				mux.Unlock()
+			},
+		})
+	}
+
+	start := time.Now()
+	mgr.Cleanup()
+
+	assert.Equal(t, 5, stopped)
+	assert.Empty(t, mgr.queue)
+	assert.Truef(t, time.Since(start) < time.Millisecond*600, "jobs are stopped in parallel")
+}
@@ -2,8 +2,10 @@ package module
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,7 +13,8 @@ import (
 	"github.com/netdata/go-orchestrator/pkg/netdataapi"
 )
 
-var writeLock = &sync.Mutex{}
+// defaultOutLock is used by the jobs that are created without the output lock.
+var defaultOutLock = &sync.Mutex{}
 
 func newRuntimeChart(pluginName string) *Chart {
 	return &Chart{
@@ -35,8 +38,20 @@ type JobConfig struct {
 	UpdateEvery     int
 	AutoDetectEvery int
 	Priority        int
+	// ChartsTypeID is the type ID of the module charts, default is the job full name.
+	ChartsTypeID string
+	// Labels are added to all the module charts.
+	Labels map[string]string
+	// OutLock serializes writes to Out, it must be shared by all the jobs writing to the same Out.
+	// Default is the package level lock.
+	OutLock sync.Locker
+	// Loggers creates the job logger, default is the logger package default factory.
+	Loggers *logger.Factory
 }
 
+// labelSourceConf is the netdata label source of the user configured labels.
+const labelSourceConf = 2
+
 const (
 	penaltyStep = 5
 	maxPenalty  = 600
@@ -45,16 +60,24 @@ const (
 
 func NewJob(cfg JobConfig) *Job {
 	var buf bytes.Buffer
+	outLock := cfg.OutLock
+	if outLock == nil {
+		outLock = defaultOutLock
+	}
 	return &Job{
 		pluginName:      cfg.PluginName,
 		name:            cfg.Name,
 		moduleName:      cfg.ModuleName,
 		fullName:        cfg.FullName,
+		chartsTypeID:    cfg.ChartsTypeID,
+		labels:          cfg.Labels,
 		updateEvery:     cfg.UpdateEvery,
 		AutoDetectEvery: cfg.AutoDetectEvery,
 		priority:        cfg.Priority,
 		module:          cfg.Module,
 		out:             cfg.Out,
+		outLock:         outLock,
+		loggers:         cfg.Loggers,
 		AutoDetectTries: infTries,
 		runChart:        newRuntimeChart(cfg.PluginName),
 		stop:            make(chan struct{}),
@@ -65,12 +88,17 @@ func NewJob(cfg JobConfig) *Job {
 }
 
 // Job represents a job. It's a module wrapper.
+// A Job is used concurrently by the run loop and its callers (the ticker, the admin API), so it must not be copied,
+// all its methods have pointer receivers.
 type Job struct {
 	pluginName string
 	name       string
 	moduleName string
 	fullName   string
 
+	chartsTypeID string
+	labels       map[string]string
+
 	updateEvery     int
 	AutoDetectEvery int
 	AutoDetectTries int
@@ -87,42 +115,66 @@ type Job struct {
 	charts   *Charts
 	tick     chan int
 	out      io.Writer
+	outLock  sync.Locker
+	loggers  *logger.Factory
 	buf      *bytes.Buffer
 	api      *netdataapi.API
 
 	retries int
 	prevRun time.Time
 
+	statsMux sync.Mutex
+	stats    JobStats
+
 	stop chan struct{}
 }
 
+// JobStats is a snapshot of the job data collection state.
+type JobStats struct {
+	// Retries is the number of consecutive failed data collections.
+	Retries int
+	// Penalty is the number of seconds added to the data collection interval because of the failures.
+	Penalty int
+	// LastError is the last data collection failure, it is kept after successful collections.
+	LastError string
+	// LastErrorTime is the time of the last data collection failure.
+	LastErrorTime time.Time
+}
+
+// Stats returns the job data collection stats. It is safe to call it concurrently with the job main loop.
+func (j *Job) Stats() JobStats {
+	j.statsMux.Lock()
+	defer j.statsMux.Unlock()
+	return j.stats
+}
+
 // FullName returns job full name.
-func (j Job) FullName() string {
+func (j *Job) FullName() string {
 	return j.fullName
 }
 
 // ModuleName returns job module name.
-func (j Job) ModuleName() string {
+func (j *Job) ModuleName() string {
 	return j.moduleName
 }
 
 // Name returns job name.
-func (j Job) Name() string {
+func (j *Job) Name() string {
 	return j.name
 }
 
 // Panicked returns 'panicked' flag value.
-func (j Job) Panicked() bool {
+func (j *Job) Panicked() bool {
 	return j.panicked
 }
 
 // AutoDetectionEvery returns value of AutoDetectEvery.
-func (j Job) AutoDetectionEvery() int {
+func (j *Job) AutoDetectionEvery() int {
 	return j.AutoDetectEvery
 }
 
 // RetryAutoDetection returns whether it is needed to retry autodetection.
-func (j Job) RetryAutoDetection() bool {
+func (j *Job) RetryAutoDetection() bool {
 	return j.AutoDetectEvery > 0 && (j.AutoDetectTries == infTries || j.AutoDetectTries > 0)
 }
 
@@ -158,6 +210,27 @@ func (j *Job) AutoDetection() (ok bool) {
 	return true
 }
 
+// Charts returns the job module charts, it is nil until the successful AutoDetection.
+func (j *Job) Charts() *Charts {
+	return j.charts
+}
+
+// CollectOnce collects metrics once without writing them to the output. It handles panic.
+// It is intended for debugging, the job must not be started.
+func (j *Job) CollectOnce() (map[string]int64, error) {
+	metrics := j.collect()
+	if j.panicked {
+		return nil, errors.New("panic during data collection")
+	}
+	return metrics, nil
+}
+
+// Cleanup cleans up the job module. It is intended for the jobs that are not started,
+// the started job module is cleaned up on Stop.
+func (j *Job) Cleanup() {
+	j.module.Cleanup()
+}
+
 // Tick Tick.
 func (j *Job) Tick(clock int) {
 	select {
@@ -201,7 +274,7 @@ func (j *Job) disableAutoDetection() {
 
 func (j *Job) cleanup() {
 	if j.Logger != nil {
-		logger.GlobalMsgCountWatcher.Unregister(j.Logger)
+		j.loggers.Unregister(j.Logger)
 	}
 	j.buf.Reset()
 
@@ -217,9 +290,9 @@ func (j *Job) cleanup() {
 			}
 		}
 	}
-	writeLock.Lock()
+	j.outLock.Lock()
 	_, _ = io.Copy(j.out, j.buf)
-	writeLock.Unlock()
+	j.outLock.Unlock()
 }
 
 func (j *Job) init() bool {
@@ -227,7 +300,7 @@ func (j *Job) init() bool {
 		return true
 	}
 
-	log := logger.NewLimited(j.ModuleName(), j.Name())
+	log := j.loggers.NewLimited(j.ModuleName(), j.Name())
 	j.Logger = log
 	j.module.GetBase().Logger = log
 
@@ -268,13 +341,15 @@ func (j *Job) runOnce() {
 
 	if j.processMetrics(metrics, curTime, sinceLastRun) {
 		j.retries = 0
+		j.updateStats("")
 	} else {
 		j.retries++
+		j.updateStats("no metrics collected")
 	}
 
-	writeLock.Lock()
+	j.outLock.Lock()
 	_, _ = io.Copy(j.out, j.buf)
-	writeLock.Unlock()
+	j.outLock.Unlock()
 	j.buf.Reset()
 }
 
@@ -284,6 +359,7 @@ func (j *Job) collect() (result map[string]int64) {
 		if r := recover(); r != nil {
 			j.Errorf("PANIC: %v", r)
 			j.panicked = true
+			j.updateStats(fmt.Sprintf("panic: %v", r))
 		}
 	}()
 	return j.module.Collect()
@@ -332,7 +408,7 @@ func (j *Job) createChart(chart *Chart) {
 		j.priority++
 	}
 	_ = j.api.CHART(
-		firstNotEmpty(chart.typeID, j.FullName()),
+		firstNotEmpty(chart.typeID, j.chartsTypeID, j.FullName()),
 		chart.ID,
 		chart.OverID,
 		chart.Title,
@@ -362,6 +438,17 @@ func (j *Job) createChart(chart *Chart) {
 			v.Value,
 		)
 	}
+	if len(j.labels) > 0 && chart != j.runChart {
+		keys := make([]string, 0, len(j.labels))
+		for k := range j.labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			_ = j.api.CLABEL(k, j.labels[k], labelSourceConf)
+		}
+		_ = j.api.CLABEL_COMMIT()
+	}
 	_ = j.api.EMPTYLINE()
 }
 
@@ -371,7 +458,7 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 	}
 
 	_ = j.api.BEGIN(
-		firstNotEmpty(chart.typeID, j.FullName()),
+		firstNotEmpty(chart.typeID, j.chartsTypeID, j.FullName()),
 		chart.ID,
 		sinceLastRun,
 	)
@@ -407,7 +494,19 @@ func (j *Job) updateChart(chart *Chart, collected map[string]int64, sinceLastRun
 	return chart.updated
 }
 
-func (j Job) penalty() int {
+func (j *Job) updateStats(errMsg string) {
+	j.statsMux.Lock()
+	defer j.statsMux.Unlock()
+
+	j.stats.Retries = j.retries
+	j.stats.Penalty = j.penalty()
+	if errMsg != "" {
+		j.stats.LastError = errMsg
+		j.stats.LastErrorTime = time.Now()
+	}
+}
+
+func (j *Job) penalty() int {
 	v := j.retries / penaltyStep * penaltyStep * j.updateEvery / 2
 	if v > maxPenalty {
 		return maxPenalty
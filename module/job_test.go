@@ -1,6 +1,7 @@
 package module
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"testing"
@@ -286,3 +287,50 @@ func TestJob_Tick(t *testing.T) {
 		job.Tick(i)
 	}
 }
+
+func TestJob_CollectOnce(t *testing.T) {
+	m := &MockModule{
+		CollectFunc: func() map[string]int64 {
+			return map[string]int64{"id1": 1}
+		},
+	}
+	job := newTestJob()
+	job.module = m
+
+	mx, err := job.CollectOnce()
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"id1": 1}, mx)
+}
+
+func TestJob_CollectOnce_Panic(t *testing.T) {
+	m := &MockModule{
+		CollectFunc: func() map[string]int64 {
+			panic("panic in Collect")
+		},
+	}
+	job := newTestJob()
+	job.module = m
+
+	mx, err := job.CollectOnce()
+
+	assert.Error(t, err)
+	assert.Nil(t, mx)
+	assert.True(t, job.Panicked())
+}
+
+func TestJob_createChart_Labels(t *testing.T) {
+	var buf bytes.Buffer
+	job := NewJob(JobConfig{
+		PluginName: pluginName,
+		Name:       jobName,
+		ModuleName: modName,
+		FullName:   modName + "_" + jobName,
+		Out:        &buf,
+		Labels:     map[string]string{"env": "prod", "dc": "eu"},
+	})
+
+	job.createChart(&Chart{ID: "id", Title: "title", Units: "units", Dims: Dims{{ID: "id1"}}})
+
+	assert.Contains(t, job.buf.String(), "CLABEL 'dc' 'eu' '2'\nCLABEL 'env' 'prod' '2'\nCLABEL_COMMIT\n")
+}
@@ -0,0 +1,132 @@
+package exec
+
+import (
+	"sort"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+type (
+	chartSpec struct {
+		ID       string    `json:"id"`
+		Title    string    `json:"title"`
+		Units    string    `json:"units"`
+		Family   string    `json:"family"`
+		Context  string    `json:"context"`
+		Type     string    `json:"type"`
+		Priority int       `json:"priority"`
+		Dims     []dimSpec `json:"dims"`
+	}
+	dimSpec struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Algorithm  string `json:"algorithm"`
+		Multiplier int    `json:"multiplier"`
+		Divisor    int    `json:"divisor"`
+		Hidden     bool   `json:"hidden"`
+	}
+)
+
+func (e *Exec) addSpecCharts(specs []chartSpec) {
+	for _, spec := range specs {
+		chart := e.charts.Get(spec.ID)
+		if chart == nil {
+			chart = newSpecChart(spec)
+			if err := e.charts.Add(chart); err != nil {
+				e.Warning(err)
+			}
+			continue
+		}
+		var added bool
+		for _, ds := range spec.Dims {
+			if chart.HasDim(ds.ID) {
+				continue
+			}
+			if err := chart.AddDim(newSpecDim(ds)); err != nil {
+				e.Warning(err)
+				continue
+			}
+			added = true
+		}
+		if added {
+			chart.MarkNotCreated()
+		}
+	}
+}
+
+func (e *Exec) addValuesDims(values map[string]int64) {
+	if e.valuesChart == nil {
+		e.valuesChart = &module.Chart{
+			ID:    "values",
+			Title: "Values of " + e.Command,
+			Units: "value",
+			Fam:   "values",
+		}
+		if err := e.charts.Add(e.valuesChart); err != nil {
+			e.Warning(err)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var added bool
+	for _, k := range keys {
+		if e.valuesChart.HasDim(k) {
+			continue
+		}
+		if err := e.valuesChart.AddDim(&module.Dim{ID: k}); err != nil {
+			e.Warning(err)
+			continue
+		}
+		added = true
+	}
+	if added {
+		e.valuesChart.MarkNotCreated()
+	}
+}
+
+func newSpecChart(spec chartSpec) *module.Chart {
+	chart := &module.Chart{
+		ID:       spec.ID,
+		Title:    spec.Title,
+		Units:    spec.Units,
+		Fam:      spec.Family,
+		Ctx:      spec.Context,
+		Type:     module.Line,
+		Priority: spec.Priority,
+	}
+	switch spec.Type {
+	case string(module.Area):
+		chart.Type = module.Area
+	case string(module.Stacked):
+		chart.Type = module.Stacked
+	}
+	for _, ds := range spec.Dims {
+		chart.Dims = append(chart.Dims, newSpecDim(ds))
+	}
+	return chart
+}
+
+func newSpecDim(spec dimSpec) *module.Dim {
+	dim := &module.Dim{
+		ID:   spec.ID,
+		Name: spec.Name,
+		Algo: module.Absolute,
+		Mul:  spec.Multiplier,
+		Div:  spec.Divisor,
+	}
+	dim.Hidden = spec.Hidden
+	switch spec.Algorithm {
+	case string(module.Incremental):
+		dim.Algo = module.Incremental
+	case string(module.PercentOfAbsolute):
+		dim.Algo = module.PercentOfAbsolute
+	case string(module.PercentOfIncremental):
+		dim.Algo = module.PercentOfIncremental
+	}
+	return dim
+}
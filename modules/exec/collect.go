@@ -0,0 +1,135 @@
+package exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const maxStderrLen = 512
+
+func (e *Exec) collect() (map[string]int64, error) {
+	bs, err := e.run()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := parseOutput(bs)
+	if err != nil {
+		return nil, fmt.Errorf("parse '%s' output: %v", e.Command, err)
+	}
+
+	if out.Charts != nil {
+		e.addSpecCharts(out.Charts)
+	} else {
+		e.addValuesDims(out.Values)
+	}
+
+	mx := make(map[string]int64, len(out.Values))
+	for k, v := range out.Values {
+		mx[k] = v
+	}
+	return mx, nil
+}
+
+func (e *Exec) run() ([]byte, error) {
+	cmd := exec.Command(e.Command, e.Args...)
+	cmd.Dir = e.WorkingDir
+	cmd.Env = os.Environ()
+	for k, v := range e.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	// the command runs in its own process group, so its children are killed on timeout as well.
+	setProcessGroup(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("'%s' execution: %v", e.Command, err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	t := time.NewTimer(e.Timeout.Duration)
+	defer t.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("'%s' execution: %v (stderr: %s)", e.Command, err, truncate(stderr.String(), maxStderrLen))
+		}
+		return stdout.Bytes(), nil
+	case <-t.C:
+		killProcessGroup(cmd)
+		<-done
+		return nil, fmt.Errorf("'%s' timed out after %s", e.Command, e.Timeout)
+	}
+}
+
+type output struct {
+	Charts []chartSpec
+	Values map[string]int64
+}
+
+// parseOutput parses either a chart spec plus values ('{"charts": [...], "values": {...}}'), or flat key/values.
+// Numbers are rounded to integers, booleans are converted to 0/1, other value types are ignored.
+func parseOutput(bs []byte) (*output, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &raw); err != nil {
+		return nil, err
+	}
+
+	if charts, ok := raw["charts"]; ok {
+		var spec struct {
+			Charts []chartSpec            `json:"charts"`
+			Values map[string]interface{} `json:"values"`
+		}
+		if err := json.Unmarshal(bs, &spec); err != nil {
+			return nil, err
+		}
+		if spec.Charts == nil || string(charts) == "null" {
+			return nil, errors.New("'charts' is empty")
+		}
+		return &output{Charts: spec.Charts, Values: toInts(spec.Values)}, nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(bs, &values); err != nil {
+		return nil, err
+	}
+	return &output{Values: toInts(values)}, nil
+}
+
+func toInts(values map[string]interface{}) map[string]int64 {
+	mx := make(map[string]int64, len(values))
+	for k, v := range values {
+		switch v := v.(type) {
+		case float64:
+			mx[k] = int64(math.Round(v))
+		case bool:
+			if v {
+				mx[k] = 1
+			} else {
+				mx[k] = 0
+			}
+		}
+	}
+	return mx
+}
+
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,95 @@
+package exec
+
+import (
+	"errors"
+	"time"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+func init() {
+	module.Register("exec", module.Creator{
+		Create: func() module.Module { return New() },
+	})
+}
+
+// New creates Exec with default values.
+func New() *Exec {
+	return &Exec{
+		Config: Config{
+			Timeout: web.Duration{Duration: time.Second * 5},
+		},
+		charts: &module.Charts{},
+	}
+}
+
+// Config is the Exec module configuration.
+type Config struct {
+	Command    string            `yaml:"command"`
+	Args       []string          `yaml:"args"`
+	Env        map[string]string `yaml:"env"`
+	WorkingDir string            `yaml:"working_dir"`
+	Timeout    web.Duration      `yaml:"timeout"`
+}
+
+// Exec runs an external command and parses its stdout as JSON.
+// The output is either flat key/values, or a chart spec plus values (see spec).
+type Exec struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	charts *module.Charts
+	// valuesChart is the chart for flat key/values output, it is created on the first such output.
+	valuesChart *module.Chart
+}
+
+// Cleanup makes cleanup.
+func (Exec) Cleanup() {}
+
+// Init makes initialization.
+func (e *Exec) Init() bool {
+	if err := e.validateConfig(); err != nil {
+		e.Errorf("validating config: %v", err)
+		return false
+	}
+	return true
+}
+
+// Check makes check.
+func (e *Exec) Check() bool {
+	mx, err := e.collect()
+	if err != nil {
+		e.Error(err)
+		return false
+	}
+	return len(mx) > 0
+}
+
+// Charts returns Charts. They are built from the command output, new charts and dimensions are added on the fly.
+func (e *Exec) Charts() *module.Charts {
+	return e.charts
+}
+
+// Collect collects metrics.
+func (e *Exec) Collect() map[string]int64 {
+	mx, err := e.collect()
+	if err != nil {
+		e.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
+
+func (e Exec) validateConfig() error {
+	if e.Command == "" {
+		return errors.New("'command' is not set")
+	}
+	if e.Timeout.Duration <= 0 {
+		return errors.New("'timeout' must be positive")
+	}
+	return nil
+}
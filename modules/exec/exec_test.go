@@ -0,0 +1,147 @@
+package exec
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestExec_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"command set":     {config: Config{Command: "sh", Timeout: web.Duration{Duration: time.Second}}},
+		"command not set": {config: New().Config, wantFail: true},
+		"zero timeout":    {config: Config{Command: "sh"}, wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := New()
+			e.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, e.Init())
+			} else {
+				assert.True(t, e.Init())
+			}
+		})
+	}
+}
+
+func TestExec_Check(t *testing.T) {
+	tests := map[string]struct {
+		prepare  func(e *Exec)
+		wantFail bool
+	}{
+		"flat values":       {prepare: prepareScript("values.sh")},
+		"chart spec":        {prepare: prepareScript("spec.sh")},
+		"command not found": {prepare: func(e *Exec) { e.Command = "not-exist-command" }, wantFail: true},
+		"command fails":     {prepare: prepareShell("exit 1"), wantFail: true},
+		"invalid output":    {prepare: prepareShell("echo not json"), wantFail: true},
+		"empty spec charts": {prepare: prepareShell(`echo '{"charts": null, "values": {"a": 1}}'`), wantFail: true},
+		"no numeric values": {prepare: prepareShell(`echo '{"a": "b"}'`), wantFail: true},
+		"command times out": {
+			prepare: func(e *Exec) {
+				prepareShell("sleep 2")(e)
+				e.Timeout = web.Duration{Duration: time.Millisecond * 100}
+			},
+			wantFail: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := New()
+			test.prepare(e)
+			require.True(t, e.Init())
+
+			if test.wantFail {
+				assert.False(t, e.Check())
+			} else {
+				assert.True(t, e.Check())
+			}
+		})
+	}
+}
+
+func TestExec_Collect_Values(t *testing.T) {
+	e := New()
+	prepareScript("values.sh")(e)
+	require.True(t, e.Init())
+
+	assert.Equal(t, map[string]int64{"requests": 10, "errors": 2, "up": 1}, e.Collect())
+
+	chart := e.Charts().Get("values")
+	require.NotNil(t, chart)
+	require.Len(t, chart.Dims, 3)
+	assert.Equal(t, "errors", chart.Dims[0].ID)
+}
+
+func TestExec_Collect_Spec(t *testing.T) {
+	e := New()
+	prepareScript("spec.sh")(e)
+	require.True(t, e.Init())
+
+	assert.Equal(t, map[string]int64{"success": 10, "failed": 2}, e.Collect())
+
+	chart := e.Charts().Get("requests")
+	require.NotNil(t, chart)
+	assert.Equal(t, module.Stacked, chart.Type)
+	assert.Equal(t, "app.requests", chart.Ctx)
+	require.Len(t, chart.Dims, 2)
+	assert.Equal(t, module.Incremental, chart.Dims[0].Algo)
+}
+
+func TestExec_Collect_EnvAndWorkingDir(t *testing.T) {
+	dir, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+
+	e := New()
+	e.Command = "sh"
+	e.Args = []string{filepath.Join(dir, "env.sh")}
+	e.Env = map[string]string{"EXEC_TEST_KEY": "from_env"}
+	e.WorkingDir = dir
+	require.True(t, e.Init())
+
+	assert.Equal(t, map[string]int64{"from_env": 1, "testdata": 2}, e.Collect())
+}
+
+func TestExec_Collect_AddsNewDims(t *testing.T) {
+	e := New()
+	prepareShell(`echo '{"a": 1}'`)(e)
+	require.True(t, e.Init())
+	require.NotNil(t, e.Collect())
+
+	prepareShell(`echo '{"a": 1, "b": 2}'`)(e)
+	require.NotNil(t, e.Collect())
+
+	chart := e.Charts().Get("values")
+	require.NotNil(t, chart)
+	assert.Len(t, chart.Dims, 2)
+}
+
+func prepareScript(name string) func(e *Exec) {
+	return func(e *Exec) {
+		e.Command = "sh"
+		e.Args = []string{filepath.Join("testdata", name)}
+	}
+}
+
+func prepareShell(script string) func(e *Exec) {
+	return func(e *Exec) {
+		e.Command = "sh"
+		e.Args = []string{"-c", script}
+	}
+}
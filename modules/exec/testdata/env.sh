@@ -0,0 +1,2 @@
+#!/bin/sh
+echo "{\"$EXEC_TEST_KEY\": 1, \"$(basename "$PWD")\": 2}"
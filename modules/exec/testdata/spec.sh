@@ -0,0 +1,20 @@
+#!/bin/sh
+cat <<JSON
+{
+  "charts": [
+    {
+      "id": "requests",
+      "title": "Requests",
+      "units": "requests/s",
+      "family": "requests",
+      "context": "app.requests",
+      "type": "stacked",
+      "dims": [
+        {"id": "success", "algorithm": "incremental"},
+        {"id": "failed", "algorithm": "incremental"}
+      ]
+    }
+  ],
+  "values": {"success": 10, "failed": 2}
+}
+JSON
@@ -0,0 +1,2 @@
+#!/bin/sh
+echo '{"requests": 10, "errors": 1.6, "up": true, "version": "1.0.0"}'
@@ -0,0 +1,99 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+func (h *HTTPJSON) collect() (map[string]int64, error) {
+	doc, err := h.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	mx := make(map[string]int64)
+	for _, dim := range h.dims {
+		v, ok := lookup(doc, dim.path)
+		if !ok {
+			h.Debugf("path '%v' not found or not a number", dim.path)
+			continue
+		}
+		mx[dim.id] = int64(math.Round(v * float64(dim.precision)))
+	}
+	return mx, nil
+}
+
+func (h *HTTPJSON) fetch() (interface{}, error) {
+	req, err := web.NewHTTPRequest(h.Request)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request to '%s': %v", h.URL, err)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on request to '%s': %v", req.URL, err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("'%s' returned HTTP status code: %d", req.URL, resp.StatusCode)
+	}
+
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error on decoding response from '%s': %v", req.URL, err)
+	}
+	return doc, nil
+}
+
+// lookup walks the decoded JSON document by the path and returns the found value as a number.
+// Numbers, booleans (0/1) and numeric strings are supported.
+func lookup(doc interface{}, path []string) (float64, bool) {
+	cur := doc
+	for _, key := range path {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[key]
+			if !ok {
+				return 0, false
+			}
+			cur = next
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return 0, false
+			}
+			cur = v[idx]
+		default:
+			return 0, false
+		}
+	}
+
+	switch v := cur.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
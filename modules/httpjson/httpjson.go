@@ -0,0 +1,135 @@
+package httpjson
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+func init() {
+	module.Register("httpjson", module.Creator{
+		Create: func() module.Module { return New() },
+	})
+}
+
+// New creates HTTPJSON with default values.
+func New() *HTTPJSON {
+	return &HTTPJSON{
+		Config: Config{
+			HTTP: web.HTTP{
+				Client: web.Client{
+					Timeout: web.Duration{Duration: time.Second * 2},
+				},
+			},
+		},
+	}
+}
+
+type (
+	// Config is the HTTPJSON module configuration.
+	Config struct {
+		web.HTTP     `yaml:",inline"`
+		ChartsConfig []ChartConfig `yaml:"charts"`
+	}
+	// ChartConfig maps JSON paths to a chart and its dimensions.
+	ChartConfig struct {
+		ID       string      `yaml:"id"`
+		Title    string      `yaml:"title"`
+		Units    string      `yaml:"units"`
+		Family   string      `yaml:"family"`
+		Context  string      `yaml:"context"`
+		Type     string      `yaml:"type"`
+		Priority int         `yaml:"priority"`
+		Dims     []DimConfig `yaml:"dims"`
+	}
+	// DimConfig maps a JSON path to a dimension.
+	// Path is a dot separated list of object keys and array indexes, e.g. 'stats.requests.0.total'.
+	// Values are multiplied by Precision before sending to keep the fractional part, the dimension divisor
+	// is multiplied by Precision as well, so it doesn't affect the units.
+	DimConfig struct {
+		Path       string `yaml:"path"`
+		ID         string `yaml:"id"`
+		Name       string `yaml:"name"`
+		Algorithm  string `yaml:"algorithm"`
+		Multiplier int    `yaml:"multiplier"`
+		Divisor    int    `yaml:"divisor"`
+		Precision  int    `yaml:"precision"`
+	}
+)
+
+// HTTPJSON collects values from a JSON HTTP endpoint using the charts mapping from the job configuration.
+type HTTPJSON struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	client *http.Client
+	charts *module.Charts
+	dims   []mappedDim
+}
+
+// Cleanup makes cleanup.
+func (h *HTTPJSON) Cleanup() {
+	if h.client == nil {
+		return
+	}
+	h.client.CloseIdleConnections()
+}
+
+// Init makes initialization.
+func (h *HTTPJSON) Init() bool {
+	if err := h.validateConfig(); err != nil {
+		h.Errorf("validating config: %v", err)
+		return false
+	}
+
+	client, err := web.NewHTTPClient(h.Client)
+	if err != nil {
+		h.Errorf("init HTTP client: %v", err)
+		return false
+	}
+	h.client = client
+
+	charts, dims, err := h.initCharts()
+	if err != nil {
+		h.Errorf("init charts: %v", err)
+		return false
+	}
+	h.charts = charts
+	h.dims = dims
+
+	return true
+}
+
+// Check makes check.
+func (h *HTTPJSON) Check() bool {
+	mx, err := h.collect()
+	if err != nil {
+		h.Error(err)
+		return false
+	}
+	if len(mx) == 0 {
+		h.Warning("none of the configured paths found in the response")
+		return false
+	}
+	return true
+}
+
+// Charts returns Charts.
+func (h *HTTPJSON) Charts() *module.Charts {
+	return h.charts
+}
+
+// Collect collects metrics.
+func (h *HTTPJSON) Collect() map[string]int64 {
+	mx, err := h.collect()
+	if err != nil {
+		h.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
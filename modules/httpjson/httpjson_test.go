@@ -0,0 +1,177 @@
+package httpjson
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+var testStatus, _ = ioutil.ReadFile("testdata/status.json")
+
+func Test_testDataIsValid(t *testing.T) {
+	require.NotNil(t, testStatus)
+}
+
+const testJobConfig = `
+charts:
+  - id: requests
+    title: Requests
+    units: requests/s
+    context: app.requests
+    type: stacked
+    dims:
+      - path: requests.total
+        name: total
+        algorithm: incremental
+      - path: requests.failed
+        id: failed
+        algorithm: incremental
+  - id: load
+    units: load
+    dims:
+      - path: load
+        precision: 100
+  - id: workers
+    dims:
+      - path: workers.0.busy
+        name: w1
+      - path: workers.1.busy
+        name: w2
+      - path: workers.2.busy
+        name: w3
+  - id: misc
+    dims:
+      - path: uptime
+      - path: healthy
+`
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestHTTPJSON_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   string
+		url      string
+		wantFail bool
+	}{
+		"valid config":    {config: testJobConfig, url: "http://127.0.0.1"},
+		"url not set":     {config: testJobConfig, wantFail: true},
+		"charts not set":  {config: "", url: "http://127.0.0.1", wantFail: true},
+		"chart id empty":  {config: "charts:\n  - dims:\n      - path: a", url: "http://127.0.0.1", wantFail: true},
+		"dims not set":    {config: "charts:\n  - id: a", url: "http://127.0.0.1", wantFail: true},
+		"dim path empty":  {config: "charts:\n  - id: a\n    dims:\n      - name: a", url: "http://127.0.0.1", wantFail: true},
+		"duplicate dim":   {config: "charts:\n  - id: a\n    dims:\n      - path: a\n      - path: a", url: "http://127.0.0.1", wantFail: true},
+		"duplicate chart": {config: "charts:\n  - id: a\n    dims:\n      - path: a\n  - id: a\n    dims:\n      - path: b", url: "http://127.0.0.1", wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := newTestHTTPJSON(t, test.config, test.url)
+
+			if test.wantFail {
+				assert.False(t, h.Init())
+			} else {
+				assert.True(t, h.Init())
+			}
+		})
+	}
+}
+
+func TestHTTPJSON_Charts(t *testing.T) {
+	h := newTestHTTPJSON(t, testJobConfig, "http://127.0.0.1")
+	require.True(t, h.Init())
+
+	charts := h.Charts()
+	require.NotNil(t, charts)
+	require.Len(t, *charts, 4)
+
+	requests := charts.Get("requests")
+	require.NotNil(t, requests)
+	assert.Equal(t, module.Stacked, requests.Type)
+	assert.Equal(t, "app.requests", requests.Ctx)
+	require.Len(t, requests.Dims, 2)
+	assert.Equal(t, "requests_requests_total", requests.Dims[0].ID)
+	assert.Equal(t, "total", requests.Dims[0].Name)
+	assert.Equal(t, module.Incremental, requests.Dims[0].Algo)
+	assert.Equal(t, "failed", requests.Dims[1].ID)
+
+	load := charts.Get("load")
+	require.NotNil(t, load)
+	assert.Equal(t, 100, load.Dims[0].Div)
+}
+
+func TestHTTPJSON_Check(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	tests := map[string]struct {
+		config   string
+		path     string
+		wantFail bool
+	}{
+		"valid response":    {config: testJobConfig, path: "/status"},
+		"no paths found":    {config: "charts:\n  - id: a\n    dims:\n      - path: not.exist", path: "/status", wantFail: true},
+		"not json response": {config: testJobConfig, path: "/text", wantFail: true},
+		"404":               {config: testJobConfig, path: "/404", wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := newTestHTTPJSON(t, test.config, srv.URL+test.path)
+			require.True(t, h.Init())
+
+			if test.wantFail {
+				assert.False(t, h.Check())
+			} else {
+				assert.True(t, h.Check())
+			}
+		})
+	}
+}
+
+func TestHTTPJSON_Collect(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	h := newTestHTTPJSON(t, testJobConfig, srv.URL+"/status")
+	require.True(t, h.Init())
+
+	expected := map[string]int64{
+		"requests_requests_total": 1024,
+		"failed":                  3,
+		"load_load":               75,
+		"workers_workers_0_busy":  2,
+		"workers_workers_1_busy":  5,
+		"misc_uptime":             3600,
+		"misc_healthy":            1,
+	}
+	assert.Equal(t, expected, h.Collect())
+}
+
+func newTestHTTPJSON(t *testing.T, config, url string) *HTTPJSON {
+	h := New()
+	require.NoError(t, yaml.Unmarshal([]byte(config), h))
+	h.URL = url
+	return h
+}
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			_, _ = w.Write(testStatus)
+		case "/text":
+			_, _ = w.Write([]byte("hello"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
@@ -0,0 +1,121 @@
+package httpjson
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/netdata/go-orchestrator/module"
+)
+
+type mappedDim struct {
+	id        string
+	path      []string
+	precision int
+}
+
+func (h HTTPJSON) validateConfig() error {
+	if h.URL == "" {
+		return errors.New("'url' is not set")
+	}
+	if len(h.ChartsConfig) == 0 {
+		return errors.New("'charts' are not set")
+	}
+	for i, c := range h.ChartsConfig {
+		if c.ID == "" {
+			return fmt.Errorf("charts[%d]: 'id' is not set", i)
+		}
+		if len(c.Dims) == 0 {
+			return fmt.Errorf("chart '%s': 'dims' are not set", c.ID)
+		}
+		for j, d := range c.Dims {
+			if d.Path == "" {
+				return fmt.Errorf("chart '%s': dims[%d]: 'path' is not set", c.ID, j)
+			}
+			if d.Precision < 0 {
+				return fmt.Errorf("chart '%s': dims[%d]: 'precision' must not be negative", c.ID, j)
+			}
+		}
+	}
+	return nil
+}
+
+func (h HTTPJSON) initCharts() (*module.Charts, []mappedDim, error) {
+	charts := &module.Charts{}
+	var dims []mappedDim
+	seen := make(map[string]bool)
+
+	for _, cc := range h.ChartsConfig {
+		chart := &module.Chart{
+			ID:       cc.ID,
+			Title:    firstNotEmpty(cc.Title, cc.ID),
+			Units:    firstNotEmpty(cc.Units, "value"),
+			Fam:      firstNotEmpty(cc.Family, cc.ID),
+			Ctx:      cc.Context,
+			Type:     module.Line,
+			Priority: cc.Priority,
+		}
+		setChartType(chart, cc.Type)
+
+		for _, dc := range cc.Dims {
+			precision := dc.Precision
+			if precision == 0 {
+				precision = 1
+			}
+			id := firstNotEmpty(dc.ID, cc.ID+"_"+strings.Replace(dc.Path, ".", "_", -1))
+			if seen[id] {
+				return nil, nil, fmt.Errorf("chart '%s': duplicate dim id '%s'", cc.ID, id)
+			}
+			seen[id] = true
+
+			divisor := dc.Divisor
+			if divisor == 0 {
+				divisor = 1
+			}
+			dim := &module.Dim{
+				ID:   id,
+				Name: firstNotEmpty(dc.Name, dc.Path),
+				Algo: module.Absolute,
+				Mul:  dc.Multiplier,
+				Div:  divisor * precision,
+			}
+			setDimAlgo(dim, dc.Algorithm)
+			chart.Dims = append(chart.Dims, dim)
+			dims = append(dims, mappedDim{id: id, path: strings.Split(dc.Path, "."), precision: precision})
+		}
+
+		if err := charts.Add(chart); err != nil {
+			return nil, nil, err
+		}
+	}
+	return charts, dims, nil
+}
+
+func setChartType(chart *module.Chart, v string) {
+	switch v {
+	case string(module.Area):
+		chart.Type = module.Area
+	case string(module.Stacked):
+		chart.Type = module.Stacked
+	}
+}
+
+func setDimAlgo(dim *module.Dim, v string) {
+	switch v {
+	case string(module.Incremental):
+		dim.Algo = module.Incremental
+	case string(module.PercentOfAbsolute):
+		dim.Algo = module.PercentOfAbsolute
+	case string(module.PercentOfIncremental):
+		dim.Algo = module.PercentOfIncremental
+	}
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/prometheus"
+)
+
+// precision is used to keep the fractional part of float values.
+const precision = 1000
+
+// idSep separates the metric name and the label values in chart and dimension IDs.
+// It is never left in a cleaned name or value, so IDs of different series don't collide.
+const idSep = "-"
+
+func newChart(id string, mf *prometheus.MetricFamily, lbs prometheus.Labels) *module.Chart {
+	chart := &module.Chart{
+		ID:    id,
+		Title: chartTitle(mf, lbs),
+		Units: chartUnits(mf),
+		Fam:   chartFamily(mf.Name),
+		Ctx:   "prometheus." + mf.Name,
+		Type:  module.Line,
+	}
+	if mf.Type == prometheus.Histogram {
+		chart.Type = module.Stacked
+	}
+	return chart
+}
+
+func newValueDim(id, name string, typ prometheus.MetricType) *module.Dim {
+	dim := &module.Dim{ID: id, Name: name, Algo: module.Absolute, Div: precision}
+	if typ == prometheus.Counter {
+		dim.Algo = module.Incremental
+	}
+	return dim
+}
+
+func newBucketDim(id string, upperBound string) *module.Dim {
+	return &module.Dim{ID: id, Name: cleanName(upperBound), Algo: module.Incremental}
+}
+
+func newQuantileDim(id string, quantile string) *module.Dim {
+	return &module.Dim{ID: id, Name: cleanName("quantile=" + quantile), Algo: module.Absolute, Div: precision}
+}
+
+func chartTitle(mf *prometheus.MetricFamily, lbs prometheus.Labels) string {
+	title := mf.Help
+	if title == "" {
+		title = mf.Name
+	}
+	if len(lbs) == 0 {
+		return cleanName(title)
+	}
+	return cleanName(fmt.Sprintf("%s (%s)", title, labelPairs(lbs)))
+}
+
+func chartUnits(mf *prometheus.MetricFamily) string {
+	name := strings.TrimSuffix(mf.Name, "_total")
+
+	var units string
+	switch {
+	case mf.Type == prometheus.Histogram:
+		return "observations/s"
+	case strings.HasSuffix(name, "_seconds"):
+		units = "seconds"
+	case strings.HasSuffix(name, "_bytes"):
+		units = "bytes"
+	case strings.HasSuffix(name, "_ratio"):
+		units = "ratio"
+	case strings.HasSuffix(name, "_percent"):
+		units = "percentage"
+	case strings.HasSuffix(name, "_celsius"):
+		units = "celsius"
+	case mf.Type == prometheus.Counter:
+		units = "events"
+	default:
+		units = "value"
+	}
+	if mf.Type == prometheus.Counter {
+		return units + "/s"
+	}
+	return units
+}
+
+func chartFamily(name string) string {
+	if idx := strings.IndexByte(name, '_'); idx > 0 {
+		return name[:idx]
+	}
+	return name
+}
+
+// chartID returns the metric family name followed by the label values separated by idSep,
+// all unsafe symbols are replaced with '_'.
+func chartID(name string, lbs prometheus.Labels) string {
+	return makeID(name, lbs)
+}
+
+// dimID returns the series ID the same way as chartID, the extra values (a bucket upper bound, a quantile) are appended.
+func dimID(name string, lbs prometheus.Labels, extra ...string) string {
+	return makeID(name, lbs, extra...)
+}
+
+func makeID(name string, lbs prometheus.Labels, extra ...string) string {
+	var b strings.Builder
+	b.WriteString(cleanID(name))
+	for _, l := range lbs {
+		b.WriteString(idSep)
+		b.WriteString(cleanID(l.Value))
+	}
+	for _, v := range extra {
+		b.WriteString(idSep)
+		b.WriteString(cleanID(v))
+	}
+	return b.String()
+}
+
+func dimName(name string, lbs prometheus.Labels) string {
+	if len(lbs) == 0 {
+		return cleanName(name)
+	}
+	return cleanName(labelPairs(lbs))
+}
+
+func labelPairs(lbs prometheus.Labels) string {
+	pairs := make([]string, 0, len(lbs))
+	for _, l := range lbs {
+		pairs = append(pairs, l.Name+"="+l.Value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func cleanID(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// cleanName removes the quotes and replaces the control characters with spaces,
+// both would break the netdata protocol line the name is written to.
+func cleanName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'':
+			return -1
+		case unicode.IsControl(r):
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// toInt64 converts the value to int64, values out of the int64 range are clamped.
+func toInt64(v float64) int64 {
+	switch {
+	case v >= math.MaxInt64:
+		return math.MaxInt64
+	case v <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
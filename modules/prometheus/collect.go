@@ -0,0 +1,219 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/prometheus"
+)
+
+// maxMisses is the number of consecutive scrapes a time series can be missing before its chart/dimension is removed.
+const maxMisses = 5
+
+type (
+	chartsCache map[string]*chartEntry
+	chartEntry  struct {
+		chart  *module.Chart
+		misses int
+		dims   map[string]int // dimension ID to the number of consecutive misses
+	}
+	// seenCache holds chart IDs and dimension IDs collected on the current scrape.
+	seenCache map[string]map[string]bool
+)
+
+func (p *Prometheus) collect() (map[string]int64, error) {
+	mfs, err := p.prom.Scrape()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.MaxTS > 0 {
+		if num := len(mfs.Series()); num > p.MaxTS {
+			return nil, fmt.Errorf("the number of time series (%d) exceeds the limit (%d)", num, p.MaxTS)
+		}
+	}
+
+	mx := make(map[string]int64)
+	seen := make(seenCache)
+
+	for _, name := range mfs.Names() {
+		mf := mfs.Get(name)
+		switch mf.Type {
+		case prometheus.Histogram:
+			p.collectHistogram(mx, seen, mf)
+		case prometheus.Summary:
+			p.collectSummary(mx, seen, mf)
+		default:
+			p.collectValue(mx, seen, mf)
+		}
+	}
+
+	p.updateCharts(seen)
+	return mx, nil
+}
+
+func (p *Prometheus) collectValue(mx map[string]int64, seen seenCache, mf *prometheus.MetricFamily) {
+	for _, m := range mf.Metrics {
+		if isInvalid(m.Value) {
+			continue
+		}
+		groupLabels, dimLabels := p.splitLabels(mf.Name, m.Labels)
+		id := chartID(mf.Name, groupLabels)
+
+		entry := p.chart(seen, id, func() *module.Chart { return newChart(id, mf, groupLabels) })
+		if entry == nil {
+			continue
+		}
+
+		sid := dimID(mf.Name, m.Labels)
+		p.dim(seen, entry, newValueDim(sid, dimName(mf.Name, dimLabels), mf.Type))
+		mx[sid] = toInt64(m.Value * precision)
+	}
+}
+
+func (p *Prometheus) collectHistogram(mx map[string]int64, seen seenCache, mf *prometheus.MetricFamily) {
+	for _, m := range mf.Metrics {
+		id := chartID(mf.Name, m.Labels)
+
+		entry := p.chart(seen, id, func() *module.Chart { return newChart(id, mf, m.Labels) })
+		if entry == nil {
+			continue
+		}
+
+		var prev float64
+		for _, b := range m.Histogram.Buckets {
+			le := formatFloat(b.UpperBound)
+			sid := dimID(mf.Name+"_bucket", m.Labels, le)
+			p.dim(seen, entry, newBucketDim(sid, le))
+			// buckets are cumulative, the chart is stacked, so every dimension is the bucket's own count.
+			mx[sid] = toInt64(b.CumulativeCount - prev)
+			prev = b.CumulativeCount
+		}
+	}
+}
+
+func (p *Prometheus) collectSummary(mx map[string]int64, seen seenCache, mf *prometheus.MetricFamily) {
+	for _, m := range mf.Metrics {
+		if len(m.Summary.Quantiles) == 0 {
+			continue
+		}
+		id := chartID(mf.Name, m.Labels)
+
+		entry := p.chart(seen, id, func() *module.Chart { return newChart(id, mf, m.Labels) })
+		if entry == nil {
+			continue
+		}
+
+		for _, q := range m.Summary.Quantiles {
+			if isInvalid(q.Value) {
+				continue
+			}
+			quantile := formatFloat(q.Quantile)
+			sid := dimID(mf.Name, m.Labels, quantile)
+			p.dim(seen, entry, newQuantileDim(sid, quantile))
+			mx[sid] = toInt64(q.Value * precision)
+		}
+	}
+}
+
+// splitLabels splits the metric labels into the labels the chart is grouped by and the rest.
+// Without a matching grouping all the metrics of a family are on one chart.
+func (p *Prometheus) splitLabels(name string, lbs prometheus.Labels) (group, rest prometheus.Labels) {
+	for _, g := range p.groups {
+		if !g.sel.Matches(name, lbs) {
+			continue
+		}
+		for _, l := range lbs {
+			if contains(g.byLabel, l.Name) {
+				group = append(group, l)
+			} else {
+				rest = append(rest, l)
+			}
+		}
+		return group, rest
+	}
+	return nil, lbs
+}
+
+func (p *Prometheus) chart(seen seenCache, id string, create func() *module.Chart) *chartEntry {
+	entry, ok := p.cache[id]
+	if !ok {
+		chart := create()
+		if err := p.charts.Add(chart); err != nil {
+			p.Warning(err)
+			return nil
+		}
+		entry = &chartEntry{chart: chart, dims: make(map[string]int)}
+		p.cache[id] = entry
+	}
+	if seen[id] == nil {
+		seen[id] = make(map[string]bool)
+	}
+	return entry
+}
+
+func (p *Prometheus) dim(seen seenCache, entry *chartEntry, dim *module.Dim) {
+	seen[entry.chart.ID][dim.ID] = true
+	if _, ok := entry.dims[dim.ID]; ok {
+		return
+	}
+
+	// the dimension could be marked for removal, but not removed by the job yet.
+	if entry.chart.HasDim(dim.ID) {
+		_ = entry.chart.RemoveDim(dim.ID)
+	}
+	if err := entry.chart.AddDim(dim); err != nil {
+		p.Warning(err)
+		return
+	}
+	entry.dims[dim.ID] = 0
+	entry.chart.MarkNotCreated()
+}
+
+// updateCharts removes charts and dimensions that are missing for maxMisses consecutive scrapes.
+func (p *Prometheus) updateCharts(seen seenCache) {
+	for id, entry := range p.cache {
+		dims, ok := seen[id]
+		if !ok {
+			if entry.misses++; entry.misses >= maxMisses {
+				p.Debugf("removing chart '%s'", id)
+				entry.chart.MarkRemove()
+				entry.chart.MarkNotCreated()
+				delete(p.cache, id)
+			}
+			continue
+		}
+
+		entry.misses = 0
+		var removed bool
+		for dimID := range entry.dims {
+			if dims[dimID] {
+				entry.dims[dimID] = 0
+				continue
+			}
+			if entry.dims[dimID]++; entry.dims[dimID] >= maxMisses {
+				p.Debugf("removing dimension '%s' from chart '%s'", dimID, id)
+				_ = entry.chart.MarkDimRemove(dimID, true)
+				delete(entry.dims, dimID)
+				removed = true
+			}
+		}
+		if removed {
+			entry.chart.MarkNotCreated()
+		}
+	}
+}
+
+func isInvalid(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
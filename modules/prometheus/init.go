@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/netdata/go-orchestrator/pkg/prometheus"
+	"github.com/netdata/go-orchestrator/pkg/prometheus/selector"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+type grouping struct {
+	sel     prometheus.Selector
+	byLabel []string
+}
+
+func (p Prometheus) validateConfig() error {
+	if p.URL == "" {
+		return errors.New("'url' is not set")
+	}
+	for i, g := range p.Groups {
+		if g.Selector == "" {
+			return fmt.Errorf("group[%d]: 'selector' is not set", i)
+		}
+		if len(g.ByLabel) == 0 {
+			return fmt.Errorf("group[%d]: 'by_label' is not set", i)
+		}
+	}
+	return nil
+}
+
+func (p Prometheus) initPrometheusClient() (prometheus.Prometheus, error) {
+	client, err := web.NewHTTPClient(p.Client)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP client: %v", err)
+	}
+
+	sel, err := p.Selector.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("parsing selector: %v", err)
+	}
+
+	return prometheus.New(client, p.Request, sel), nil
+}
+
+func (p Prometheus) initGroupings() ([]grouping, error) {
+	var groups []grouping
+	for i, g := range p.Groups {
+		sel, err := selector.Parse(g.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("group[%d]: %v", i, err)
+		}
+		groups = append(groups, grouping{sel: sel, byLabel: g.ByLabel})
+	}
+	return groups, nil
+}
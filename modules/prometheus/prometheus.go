@@ -0,0 +1,119 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/prometheus"
+	"github.com/netdata/go-orchestrator/pkg/prometheus/selector"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+func init() {
+	module.Register("prometheus", module.Creator{
+		Create: func() module.Module { return New() },
+	})
+}
+
+// New creates Prometheus with default values.
+func New() *Prometheus {
+	return &Prometheus{
+		Config: Config{
+			HTTP: web.HTTP{
+				Client: web.Client{
+					Timeout: web.Duration{Duration: time.Second * 5},
+				},
+			},
+			MaxTS: 2000,
+		},
+		charts: &module.Charts{},
+		cache:  make(chartsCache),
+	}
+}
+
+type (
+	// Config is the Prometheus module configuration.
+	Config struct {
+		web.HTTP `yaml:",inline"`
+		Selector selector.Expr `yaml:"selector"`
+		Groups   []GroupOption `yaml:"group"`
+		// MaxTS is the maximum number of time series per scrape, zero means no limit.
+		MaxTS int `yaml:"max_time_series"`
+	}
+	// GroupOption splits charts of the metric families that match the selector
+	// into one chart per distinct combination of the given label values.
+	GroupOption struct {
+		Selector string   `yaml:"selector"`
+		ByLabel  []string `yaml:"by_label"`
+	}
+)
+
+// Prometheus scrapes a Prometheus endpoint and builds charts from the metric metadata.
+type Prometheus struct {
+	module.Base
+	Config `yaml:",inline"`
+
+	prom   prometheus.Prometheus
+	groups []grouping
+	charts *module.Charts
+	cache  chartsCache
+}
+
+// Cleanup makes cleanup.
+func (Prometheus) Cleanup() {}
+
+// Init makes initialization.
+func (p *Prometheus) Init() bool {
+	if err := p.validateConfig(); err != nil {
+		p.Errorf("validating config: %v", err)
+		return false
+	}
+
+	prom, err := p.initPrometheusClient()
+	if err != nil {
+		p.Errorf("init prometheus client: %v", err)
+		return false
+	}
+	p.prom = prom
+
+	groups, err := p.initGroupings()
+	if err != nil {
+		p.Errorf("init groupings: %v", err)
+		return false
+	}
+	p.groups = groups
+
+	return true
+}
+
+// Check makes check.
+func (p *Prometheus) Check() bool {
+	mfs, err := p.prom.Scrape()
+	if err != nil {
+		p.Error(err)
+		return false
+	}
+	if mfs.Len() == 0 {
+		p.Warning("endpoint returned no metrics (or all metrics are filtered out)")
+		return false
+	}
+	return true
+}
+
+// Charts returns Charts. It is empty on start, charts are added (and removed) during data collection.
+func (p *Prometheus) Charts() *module.Charts {
+	return p.charts
+}
+
+// Collect collects metrics.
+func (p *Prometheus) Collect() map[string]int64 {
+	mx, err := p.collect()
+	if err != nil {
+		p.Error(err)
+	}
+
+	if len(mx) == 0 {
+		return nil
+	}
+	return mx
+}
@@ -0,0 +1,259 @@
+package prometheus
+
+import (
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/prometheus/selector"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMetrics, _ = ioutil.ReadFile("testdata/metrics.txt")
+
+func Test_testDataIsValid(t *testing.T) {
+	require.NotNil(t, testMetrics)
+}
+
+func TestNew(t *testing.T) {
+	assert.Implements(t, (*module.Module)(nil), New())
+}
+
+func TestPrometheus_Init(t *testing.T) {
+	tests := map[string]struct {
+		config   Config
+		wantFail bool
+	}{
+		"default with URL": {config: func() Config { c := New().Config; c.URL = "http://127.0.0.1"; return c }()},
+		"URL not set":      {config: New().Config, wantFail: true},
+		"invalid selector": {
+			config:   Config{Selector: selector.Expr{Allow: []string{"["}}},
+			wantFail: true,
+		},
+		"group without selector": {
+			config: func() Config {
+				c := New().Config
+				c.URL = "http://127.0.0.1"
+				c.Groups = []GroupOption{{ByLabel: []string{"a"}}}
+				return c
+			}(),
+			wantFail: true,
+		},
+		"group without labels": {
+			config: func() Config {
+				c := New().Config
+				c.URL = "http://127.0.0.1"
+				c.Groups = []GroupOption{{Selector: "*"}}
+				return c
+			}(),
+			wantFail: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prom := New()
+			prom.Config = test.config
+
+			if test.wantFail {
+				assert.False(t, prom.Init())
+			} else {
+				assert.True(t, prom.Init())
+			}
+		})
+	}
+}
+
+func TestPrometheus_Check(t *testing.T) {
+	srv, _ := newTestServer()
+	defer srv.Close()
+
+	tests := map[string]struct {
+		path     string
+		selector selector.Expr
+		wantFail bool
+	}{
+		"valid metrics":            {path: "/metrics"},
+		"all metrics filtered out": {path: "/metrics", selector: selector.Expr{Allow: []string{"not_exist"}}, wantFail: true},
+		"404":                      {path: "/404", wantFail: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prom := New()
+			prom.URL = srv.URL + test.path
+			prom.Selector = test.selector
+			require.True(t, prom.Init())
+
+			if test.wantFail {
+				assert.False(t, prom.Check())
+			} else {
+				assert.True(t, prom.Check())
+			}
+		})
+	}
+}
+
+func TestPrometheus_Collect(t *testing.T) {
+	srv, _ := newTestServer()
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL + "/metrics"
+	require.True(t, prom.Init())
+	require.True(t, prom.Check())
+
+	charts := prom.Charts()
+	require.NotNil(t, charts)
+	assert.Len(t, *charts, 0)
+
+	expected := map[string]int64{
+		"go_goroutines":                             13000,
+		"http_requests_total-200-get":               10000,
+		"http_requests_total-200-post":              1027000,
+		"http_requests_total-400-post":              3000,
+		"http_request_duration_seconds_bucket-0_05": 24,
+		"http_request_duration_seconds_bucket-0_1":  9,
+		"http_request_duration_seconds_bucket-_Inf": 111,
+		"rpc_duration_seconds-0_5":                  500,
+		"rpc_duration_seconds-0_99":                 1250,
+	}
+	assert.Equal(t, expected, prom.Collect())
+
+	require.Len(t, *charts, 4)
+	assertChart(t, charts, "go_goroutines", module.Line, "value", module.Absolute, 1)
+	assertChart(t, charts, "http_requests_total", module.Line, "events/s", module.Incremental, 3)
+	assertChart(t, charts, "http_request_duration_seconds", module.Stacked, "observations/s", module.Incremental, 3)
+	assertChart(t, charts, "rpc_duration_seconds", module.Line, "seconds", module.Absolute, 2)
+}
+
+func TestPrometheus_Collect_Grouping(t *testing.T) {
+	srv, _ := newTestServer()
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL + "/metrics"
+	prom.Groups = []GroupOption{{Selector: "http_requests_total", ByLabel: []string{"method"}}}
+	require.True(t, prom.Init())
+	require.NotNil(t, prom.Collect())
+
+	charts := prom.Charts()
+	assertChart(t, charts, "http_requests_total-get", module.Line, "events/s", module.Incremental, 1)
+	assertChart(t, charts, "http_requests_total-post", module.Line, "events/s", module.Incremental, 2)
+	assert.Nil(t, charts.Get("http_requests_total"))
+}
+
+func TestPrometheus_Collect_RemovesStaleCharts(t *testing.T) {
+	srv, data := newTestServer()
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL + "/metrics"
+	require.True(t, prom.Init())
+	require.NotNil(t, prom.Collect())
+
+	data.set([]byte("# TYPE http_requests_total counter\nhttp_requests_total{method=\"post\",code=\"200\"} 1028\n"))
+
+	for i := 0; i < maxMisses; i++ {
+		require.NotNil(t, prom.Collect())
+	}
+
+	for _, chart := range *prom.Charts() {
+		if chart.ID == "http_requests_total" {
+			assert.False(t, chart.Obsolete)
+			assert.Len(t, chart.Dims, 3)
+			assert.False(t, chart.GetDim("http_requests_total-200-post").Obsolete)
+			assert.True(t, chart.GetDim("http_requests_total-400-post").Obsolete)
+			assert.True(t, chart.GetDim("http_requests_total-200-get").Obsolete)
+			continue
+		}
+		assert.Truef(t, chart.Obsolete, "chart '%s' is not obsolete", chart.ID)
+	}
+	assert.Len(t, prom.cache, 1)
+}
+
+func TestPrometheus_Collect_UnsafeSeries(t *testing.T) {
+	srv, data := newTestServer()
+	defer srv.Close()
+	data.set([]byte(`# TYPE a_b gauge
+a_b{x="c"} 1
+# TYPE a_b_c gauge
+a_b_c 2
+# TYPE big_total counter
+big_total 1e19
+# HELP quoted it's quoted.
+# TYPE quoted gauge
+quoted{x="it's\nme"} 3
+`))
+
+	prom := New()
+	prom.URL = srv.URL + "/metrics"
+	prom.Groups = []GroupOption{{Selector: "a_b", ByLabel: []string{"x"}}}
+	require.True(t, prom.Init())
+
+	expected := map[string]int64{
+		"a_b-c":          1000,
+		"a_b_c":          2000,
+		"big_total":      math.MaxInt64,
+		"quoted-it_s_me": 3000,
+	}
+	assert.Equal(t, expected, prom.Collect())
+
+	charts := prom.Charts()
+	assertChart(t, charts, "a_b-c", module.Line, "value", module.Absolute, 1)
+	assertChart(t, charts, "a_b_c", module.Line, "value", module.Absolute, 1)
+	chart := charts.Get("quoted")
+	require.NotNil(t, chart)
+	assert.Equal(t, "its quoted.", chart.Title)
+	assert.Equal(t, "x=its me", chart.GetDim("quoted-it_s_me").Name)
+}
+
+func TestPrometheus_Collect_TimeSeriesLimit(t *testing.T) {
+	srv, _ := newTestServer()
+	defer srv.Close()
+
+	prom := New()
+	prom.URL = srv.URL + "/metrics"
+	prom.MaxTS = 5
+	require.True(t, prom.Init())
+
+	assert.Nil(t, prom.Collect())
+}
+
+func assertChart(t *testing.T, charts *module.Charts, id string, typ interface{}, units string, algo interface{}, numDims int) {
+	t.Helper()
+	chart := charts.Get(id)
+	require.NotNilf(t, chart, "chart '%s'", id)
+	assert.EqualValues(t, typ, chart.Type)
+	assert.Equal(t, units, chart.Units)
+	require.Len(t, chart.Dims, numDims)
+	for _, dim := range chart.Dims {
+		assert.EqualValues(t, algo, dim.Algo)
+	}
+}
+
+type testData struct {
+	mux sync.Mutex
+	bs  []byte
+}
+
+func (d *testData) set(bs []byte) { d.mux.Lock(); defer d.mux.Unlock(); d.bs = bs }
+func (d *testData) get() []byte   { d.mux.Lock(); defer d.mux.Unlock(); return d.bs }
+
+func newTestServer() (*httptest.Server, *testData) {
+	data := &testData{bs: testMetrics}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data.get())
+	}))
+	return srv, data
+}
@@ -10,9 +10,11 @@ var (
 	resetEvery = time.Second
 )
 
-// GlobalMsgCountWatcher is a initiated instance of MsgCountWatcher.
+// GlobalMsgCountWatcher is the default factory MsgCountWatcher.
 // It resets message counter for every registered logger every 1 seconds.
-var GlobalMsgCountWatcher = newMsgCountWatcher(resetEvery)
+//
+// Deprecated: use Default().NewLimited and Default().Unregister.
+var GlobalMsgCountWatcher = defaultFactory.msgCountWatcher()
 
 func newMsgCountWatcher(resetEvery time.Duration) *MsgCountWatcher {
 	t := &MsgCountWatcher{
@@ -25,7 +27,7 @@ func newMsgCountWatcher(resetEvery time.Duration) *MsgCountWatcher {
 	return t
 }
 
-// MsgCountWatcher MsgCountWatcher
+// MsgCountWatcher resets message counter for every registered logger every second.
 type MsgCountWatcher struct {
 	shutdown chan struct{}
 	ticker   *time.Ticker
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Factory creates loggers that share the output prefix, the severity level, the rate limit watcher
+// and the dropped messages counter. Every embedded plugin instance can have its own factory.
+// A nil *Factory is usable, it is the default factory.
+type Factory struct {
+	out      io.Writer
+	severity int64
+
+	mux    sync.Mutex
+	prefix string
+
+	watcherOnce sync.Once
+	watcher     *MsgCountWatcher
+
+	dropped int64
+}
+
+// the default factory prefix is not set, Prefix is used until it is.
+var defaultFactory = NewFactory("")
+
+// Prefix is the default factory loggers output prefix if it is not set with SetPrefix.
+//
+// Deprecated: use Default().SetPrefix.
+var Prefix = "goplugin"
+
+// NewFactory creates a new logger factory, the loggers write to stderr, the severity level is INFO.
+func NewFactory(prefix string) *Factory {
+	return &Factory{
+		out:      os.Stderr,
+		severity: int64(INFO),
+		prefix:   prefix,
+	}
+}
+
+// Default returns the default factory. It is used by the package level functions.
+func Default() *Factory {
+	return defaultFactory
+}
+
+// New creates a new logger.
+func (f *Factory) New(modName, jobName string) *Logger {
+	f = f.orDefault()
+	f.mux.Lock()
+	prefix := f.prefix
+	f.mux.Unlock()
+	if prefix == "" && f == defaultFactory {
+		prefix = Prefix
+	}
+
+	return &Logger{
+		factory:   f,
+		formatter: newFormatter(f.out, isCLI, prefix),
+		modName:   modName,
+		jobName:   jobName,
+		id:        uniqueID(),
+	}
+}
+
+// NewLimited creates a new limited logger, it drops messages above the per second limit
+// unless the severity level is DEBUG. It should be unregistered when it is no longer used.
+func (f *Factory) NewLimited(modName, jobName string) *Logger {
+	f = f.orDefault()
+	l := f.New(modName, jobName)
+	l.limited = true
+	f.msgCountWatcher().Register(l)
+	return l
+}
+
+// Unregister stops the limited logger messages counter reset.
+func (f *Factory) Unregister(l *Logger) {
+	f = f.orDefault()
+	if l != nil && l.limited {
+		f.msgCountWatcher().Unregister(l)
+	}
+}
+
+// SetPrefix sets the output prefix of the loggers created after the call.
+func (f *Factory) SetPrefix(prefix string) {
+	f = f.orDefault()
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.prefix = prefix
+}
+
+// SetSeverity sets the severity level of all the factory loggers.
+func (f *Factory) SetSeverity(severity Severity) {
+	atomic.StoreInt64(&f.orDefault().severity, int64(severity))
+}
+
+// Severity returns the severity level of the factory loggers.
+func (f *Factory) Severity() Severity {
+	return Severity(atomic.LoadInt64(&f.orDefault().severity))
+}
+
+// Dropped returns the number of messages dropped by the factory limited loggers because of the rate limit.
+func (f *Factory) Dropped() int64 {
+	return atomic.LoadInt64(&f.orDefault().dropped)
+}
+
+func (f *Factory) msgCountWatcher() *MsgCountWatcher {
+	// the watcher goroutine is started only if there are limited loggers.
+	f.watcherOnce.Do(func() { f.watcher = newMsgCountWatcher(resetEvery) })
+	return f.watcher
+}
+
+func (f *Factory) orDefault() *Factory {
+	if f == nil {
+		return defaultFactory
+	}
+	return f
+}
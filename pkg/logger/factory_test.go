@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactory_isolation(t *testing.T) {
+	f1, f2 := NewFactory("plugin1"), NewFactory("plugin2")
+	f2.SetSeverity(ERROR)
+
+	var buf1, buf2 bytes.Buffer
+	l1, l2 := f1.NewLimited("module", "job"), f2.NewLimited("module", "job")
+	defer f1.Unregister(l1)
+	defer f2.Unregister(l2)
+	l1.formatter.SetOutput(&buf1)
+	l2.formatter.SetOutput(&buf2)
+
+	for i := 0; i < msgPerSecondLimit+10; i++ {
+		l1.Info("message")
+		l2.Info("message")
+	}
+
+	assert.Equal(t, INFO, f1.Severity())
+	assert.Equal(t, ERROR, f2.Severity())
+	assert.Equal(t, int64(10), f1.Dropped())
+	assert.Equal(t, int64(0), f2.Dropped())
+	assert.NotEmpty(t, buf1.String())
+	assert.Empty(t, buf2.String())
+}
+
+func TestFactory_SetPrefix(t *testing.T) {
+	f := NewFactory("plugin1")
+	f.SetPrefix("plugin2")
+
+	l := f.New("module", "job")
+
+	if !isCLI {
+		assert.Equal(t, "plugin2 ", l.formatter.prefix)
+	}
+}
+
+func TestFactory_nil(t *testing.T) {
+	var f *Factory
+
+	l := f.NewLimited("module", "job")
+	defer f.Unregister(l)
+
+	require.NotNil(t, l)
+	assert.Equal(t, Default(), l.factory)
+	assert.Equal(t, Default().Severity(), f.Severity())
+}
+
+func TestPrefix(t *testing.T) {
+	defer func(prefix string) { Prefix = prefix }(Prefix)
+	Prefix = "plugin1"
+
+	l := New("module", "job")
+
+	if !isCLI {
+		assert.Equal(t, "plugin1 ", l.formatter.prefix)
+	}
+	assert.Equal(t, Default().msgCountWatcher(), GlobalMsgCountWatcher)
+}
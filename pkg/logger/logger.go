@@ -25,11 +25,16 @@ var (
 			return isatty.IsTerminal(os.Stderr.Fd())
 		}
 	}()
-	Prefix = "goplugin"
 )
 
+// Dropped returns the number of messages dropped by the default factory limited loggers because of the rate limit.
+func Dropped() int64 {
+	return defaultFactory.Dropped()
+}
+
 // Logger represents a logger object
 type Logger struct {
+	factory   *Factory
 	formatter *formatter
 
 	id      int64
@@ -40,23 +45,14 @@ type Logger struct {
 	msgCount int64
 }
 
-// New creates a new logger.
+// New creates a new logger using the default factory.
 func New(modName, jobName string) *Logger {
-	return &Logger{
-		formatter: newFormatter(os.Stderr, isCLI, Prefix),
-		modName:   modName,
-		jobName:   jobName,
-		id:        uniqueID(),
-	}
+	return defaultFactory.New(modName, jobName)
 }
 
-// NewLimited creates a new limited logger
+// NewLimited creates a new limited logger using the default factory.
 func NewLimited(modName, jobName string) *Logger {
-	logger := New(modName, jobName)
-	logger.limited = true
-	GlobalMsgCountWatcher.Register(logger)
-
-	return logger
+	return defaultFactory.NewLimited(modName, jobName)
 }
 
 // Panic logs a message with the Critical severity then panic
@@ -171,16 +167,19 @@ func (l *Logger) Debugf(format string, a ...interface{}) {
 }
 
 func (l *Logger) output(severity Severity, callDepth int, msg string) {
-	if severity > globalSeverity {
+	if l == nil || l.formatter == nil {
+		if severity <= base.factory.Severity() {
+			base.formatter.Output(severity, base.modName, base.jobName, callDepth+2, msg)
+		}
 		return
 	}
 
-	if l == nil || l.formatter == nil {
-		base.formatter.Output(severity, base.modName, base.jobName, callDepth+2, msg)
+	level := l.factory.Severity()
+	if severity > level {
 		return
 	}
-
-	if l.limited && globalSeverity < DEBUG && atomic.AddInt64(&l.msgCount, 1) > msgPerSecondLimit {
+	if l.limited && level < DEBUG && atomic.AddInt64(&l.msgCount, 1) > msgPerSecondLimit {
+		atomic.AddInt64(&l.factory.orDefault().dropped, 1)
 		return
 	}
 	l.formatter.Output(severity, l.modName, l.jobName, callDepth+2, msg)
@@ -12,10 +12,36 @@ import (
 )
 
 func TestSetSeverity(t *testing.T) {
-	require.Equal(t, globalSeverity, INFO)
+	require.Equal(t, Default().Severity(), INFO)
 	SetSeverity(DEBUG)
 
-	assert.Equal(t, globalSeverity, DEBUG)
+	assert.Equal(t, Default().Severity(), DEBUG)
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		want    Severity
+		wantErr bool
+	}{
+		"full name":       {name: "warning", want: WARNING},
+		"upper case name": {name: "DEBUG", want: DEBUG},
+		"short name":      {name: "crit", want: CRITICAL},
+		"unknown name":    {name: "verbose", want: INFO, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := ParseSeverity(test.name)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, s)
+		})
+	}
 }
 
 func TestNew(t *testing.T) {
@@ -30,9 +56,9 @@ func TestNewLimited(t *testing.T) {
 	logger := NewLimited("", "")
 	assert.True(t, logger.limited)
 
-	_, ok := GlobalMsgCountWatcher.items[logger.id]
+	_, ok := Default().msgCountWatcher().items[logger.id]
 	require.True(t, ok)
-	GlobalMsgCountWatcher.Unregister(logger)
+	Default().Unregister(logger)
 }
 
 func TestLogger_Critical(t *testing.T) {
@@ -210,3 +236,20 @@ func BenchmarkLog_Printf(b *testing.B) {
 		logger.Printf("hello %s", "world")
 	}
 }
+
+func TestDropped(t *testing.T) {
+	sev := Default().Severity()
+	defer SetSeverity(sev)
+	SetSeverity(INFO)
+
+	logger := New("", "")
+	logger.limited = true
+	logger.formatter.SetOutput(&bytes.Buffer{})
+	before := Dropped()
+
+	for i := 0; i < msgPerSecondLimit+10; i++ {
+		logger.Info()
+	}
+
+	assert.Equal(t, int64(10), Dropped()-before)
+}
@@ -1,6 +1,9 @@
 package logger
 
-var globalSeverity = INFO
+import (
+	"fmt"
+	"strings"
+)
 
 // Severity is a logging severity level
 type Severity int
@@ -52,7 +55,17 @@ func (s Severity) ShortString() string {
 	return "UNKNOWN"
 }
 
-// SetSeverity sets global severity level
+// SetSeverity sets the default factory severity level
 func SetSeverity(severity Severity) {
-	globalSeverity = severity
+	defaultFactory.SetSeverity(severity)
+}
+
+// ParseSeverity returns the severity level by its name (case insensitive), short names are accepted too.
+func ParseSeverity(name string) (Severity, error) {
+	for _, s := range []Severity{CRITICAL, ERROR, WARNING, INFO, DEBUG} {
+		if strings.EqualFold(name, s.String()) || strings.EqualFold(name, s.ShortString()) {
+			return s, nil
+		}
+	}
+	return INFO, fmt.Errorf("unknown severity level '%s'", name)
 }
@@ -0,0 +1,95 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Config is the log collector configuration. This structure is intended to be part of the module configuration.
+// Supported configuration file formats: YAML.
+type Config struct {
+	Path         string         `yaml:"path"`
+	PositionFile string         `yaml:"position_file"`
+	Parser       ParserConfig   `yaml:"parser"`
+	Metrics      []MetricConfig `yaml:"metrics"`
+}
+
+// Collector ties a Reader, a Parser and an Aggregator together.
+// It is intended to be used in the Module.Collect.
+type Collector struct {
+	reader *Reader
+	parser Parser
+	agg    *Aggregator
+
+	lines    int64
+	unparsed int64
+}
+
+// Stats is the number of read and not parsed lines since the Collector creation.
+type Stats struct {
+	Lines    int64
+	Unparsed int64
+}
+
+// NewCollector creates a Collector and opens the log file.
+func NewCollector(cfg Config) (*Collector, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("'path' is not set")
+	}
+	if len(cfg.Metrics) == 0 {
+		return nil, errors.New("'metrics' are not set")
+	}
+
+	parser, err := NewParser(cfg.Parser)
+	if err != nil {
+		return nil, err
+	}
+	agg, err := NewAggregator(cfg.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := Open(cfg.Path, cfg.PositionFile)
+	if err != nil {
+		return nil, fmt.Errorf("open '%s': %v", cfg.Path, err)
+	}
+
+	return &Collector{reader: reader, parser: parser, agg: agg}, nil
+}
+
+// Collect reads and parses new lines, and returns the aggregated values.
+func (c *Collector) Collect() (map[string]int64, error) {
+	offset := c.reader.Offset()
+
+	err := c.reader.ReadLines(func(line []byte) {
+		c.lines++
+		fields, err := c.parser.Parse(line)
+		if err != nil {
+			c.unparsed++
+			return
+		}
+		c.agg.Observe(fields)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.reader.Offset() != offset {
+		if err := c.reader.SavePosition(); err != nil {
+			return nil, fmt.Errorf("save position: %v", err)
+		}
+	}
+
+	mx := make(map[string]int64)
+	c.agg.Collect(mx)
+	return mx, nil
+}
+
+// Stats returns the read lines statistics.
+func (c *Collector) Stats() Stats {
+	return Stats{Lines: c.lines, Unparsed: c.unparsed}
+}
+
+// Close closes the log file.
+func (c *Collector) Close() error {
+	return c.reader.Close()
+}
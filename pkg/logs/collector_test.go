@@ -0,0 +1,80 @@
+package logs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCollector(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "app.log")
+	writeFile(t, path, "")
+
+	validParser := ParserConfig{LogType: TypeJSON}
+	validMetrics := []MetricConfig{{Name: "lines"}}
+
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"valid":              {cfg: Config{Path: path, Parser: validParser, Metrics: validMetrics}},
+		"path not set":       {cfg: Config{Parser: validParser, Metrics: validMetrics}, wantErr: true},
+		"file doesnt exist":  {cfg: Config{Path: path + ".1", Parser: validParser, Metrics: validMetrics}, wantErr: true},
+		"metrics not set":    {cfg: Config{Path: path, Parser: validParser}, wantErr: true},
+		"invalid parser":     {cfg: Config{Path: path, Metrics: validMetrics}, wantErr: true},
+		"invalid metric cfg": {cfg: Config{Path: path, Parser: validParser, Metrics: []MetricConfig{{}}}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewCollector(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, c.Close())
+			}
+		})
+	}
+}
+
+func TestCollector_Collect(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "app.log")
+	writeFile(t, path, `{"status":500}`+"\n")
+
+	cfg := Config{
+		Path:         path,
+		PositionFile: filepath.Join(dir, "pos.json"),
+		Parser:       ParserConfig{LogType: TypeJSON},
+		Metrics:      []MetricConfig{{Name: "requests", Field: "status"}},
+	}
+	c, err := NewCollector(cfg)
+	require.NoError(t, err)
+
+	mx, err := c.Collect()
+	require.NoError(t, err)
+	assert.Empty(t, mx)
+
+	appendFile(t, path, `{"status":200}`+"\ngarbage\n"+`{"status":404}`+"\n")
+	mx, err = c.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"requests_200": 1, "requests_404": 1}, mx)
+	assert.Equal(t, Stats{Lines: 3, Unparsed: 1}, c.Stats())
+	require.NoError(t, c.Close())
+
+	appendFile(t, path, `{"status":200}`+"\n")
+	c, err = NewCollector(cfg)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	mx, err = c.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"requests_200": 1}, mx)
+}
@@ -0,0 +1,69 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+)
+
+// CSVConfig is the CSV parser configuration.
+// Fields named '-' (or empty) are skipped.
+type CSVConfig struct {
+	Delimiter        string   `yaml:"delimiter"`
+	TrimLeadingSpace bool     `yaml:"trim_leading_space"`
+	Fields           []string `yaml:"fields"`
+}
+
+// CSVParser parses delimiter separated lines.
+type CSVParser struct {
+	comma            rune
+	trimLeadingSpace bool
+	fields           []string
+}
+
+// NewCSVParser creates a CSVParser. Delimiter defaults to ','.
+func NewCSVParser(cfg CSVConfig) (*CSVParser, error) {
+	if len(cfg.Fields) == 0 {
+		return nil, errors.New("csv parser: 'fields' are not set")
+	}
+	comma := ','
+	if cfg.Delimiter != "" {
+		runes := []rune(cfg.Delimiter)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("csv parser: invalid delimiter '%s'", cfg.Delimiter)
+		}
+		comma = runes[0]
+	}
+	return &CSVParser{
+		comma:            comma,
+		trimLeadingSpace: cfg.TrimLeadingSpace,
+		fields:           cfg.Fields,
+	}, nil
+}
+
+// Parse parses the line.
+func (p *CSVParser) Parse(line []byte) (Fields, error) {
+	rd := csv.NewReader(bytes.NewReader(line))
+	rd.Comma = p.comma
+	rd.TrimLeadingSpace = p.trimLeadingSpace
+	rd.LazyQuotes = true
+	rd.FieldsPerRecord = -1
+
+	record, err := rd.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv parser: %v", err)
+	}
+	if len(record) != len(p.fields) {
+		return nil, ErrLineMismatch
+	}
+
+	fields := make(Fields, len(record))
+	for i, name := range p.fields {
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = record[i]
+	}
+	return fields, nil
+}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package logs
+
+import "os"
+
+// inode is not available on windows, rotation is detected with os.SameFile only.
+func inode(_ os.FileInfo) uint64 { return 0 }
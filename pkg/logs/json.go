@@ -0,0 +1,67 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// JSONConfig is the JSON parser configuration.
+// Nested objects are flattened, keys are joined with the separator.
+type JSONConfig struct {
+	Separator string `yaml:"separator"`
+}
+
+// JSONParser parses lines that are JSON objects.
+type JSONParser struct {
+	sep string
+}
+
+// NewJSONParser creates a JSONParser. Separator defaults to '_'.
+func NewJSONParser(cfg JSONConfig) (*JSONParser, error) {
+	sep := cfg.Separator
+	if sep == "" {
+		sep = "_"
+	}
+	return &JSONParser{sep: sep}, nil
+}
+
+// Parse parses the line.
+func (p *JSONParser) Parse(line []byte) (Fields, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("json parser: %v", err)
+	}
+
+	fields := make(Fields)
+	p.flatten(fields, "", obj)
+	return fields, nil
+}
+
+func (p *JSONParser) flatten(fields Fields, prefix string, obj map[string]interface{}) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + p.sep + k
+		}
+		switch v := v.(type) {
+		case map[string]interface{}:
+			p.flatten(fields, key, v)
+		case json.Number:
+			fields[key] = v.String()
+		case string:
+			fields[key] = v
+		case bool:
+			fields[key] = strconv.FormatBool(v)
+		case nil:
+			fields[key] = ""
+		default:
+			bs, _ := json.Marshal(v)
+			fields[key] = string(bs)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"bytes"
+	"errors"
+)
+
+// LTSVConfig is the LTSV (Labeled Tab-separated Values, http://ltsv.org/) parser configuration.
+type LTSVConfig struct {
+	FieldDelimiter string `yaml:"field_delimiter"`
+	ValueDelimiter string `yaml:"value_delimiter"`
+}
+
+// LTSVParser parses 'label:value' pairs separated by tabs.
+type LTSVParser struct {
+	fieldDelim []byte
+	valueDelim []byte
+}
+
+// NewLTSVParser creates a LTSVParser. Delimiters default to '\t' and ':'.
+func NewLTSVParser(cfg LTSVConfig) (*LTSVParser, error) {
+	p := &LTSVParser{fieldDelim: []byte("\t"), valueDelim: []byte(":")}
+	if cfg.FieldDelimiter != "" {
+		p.fieldDelim = []byte(cfg.FieldDelimiter)
+	}
+	if cfg.ValueDelimiter != "" {
+		p.valueDelim = []byte(cfg.ValueDelimiter)
+	}
+	if bytes.Equal(p.fieldDelim, p.valueDelim) {
+		return nil, errors.New("ltsv parser: field and value delimiters are the same")
+	}
+	return p, nil
+}
+
+// Parse parses the line.
+func (p *LTSVParser) Parse(line []byte) (Fields, error) {
+	fields := make(Fields)
+	for _, pair := range bytes.Split(line, p.fieldDelim) {
+		idx := bytes.Index(pair, p.valueDelim)
+		if idx <= 0 {
+			return nil, ErrLineMismatch
+		}
+		fields[string(pair[:idx])] = string(pair[idx+len(p.valueDelim):])
+	}
+	return fields, nil
+}
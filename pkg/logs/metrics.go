@@ -0,0 +1,236 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MetricCounter counts lines, or lines per distinct value of the field if the field is set.
+	MetricCounter = "counter"
+	// MetricSum sums numeric values of the field.
+	MetricSum = "sum"
+	// MetricHistogram counts numeric values of the field into cumulative buckets.
+	MetricHistogram = "histogram"
+)
+
+const (
+	defaultMaxValues = 100
+	// otherValue is the counter key part of the field values over the MaxValues limit.
+	otherValue = "other"
+)
+
+// MetricConfig is an aggregated metric configuration.
+//
+// Keys of the collected values:
+//   - counter: '<name>', or '<name>_<field value>' if the field is set. Only the first MaxValues (default 100)
+//     distinct values are counted separately, the rest are counted as '<name>_other'.
+//   - sum: '<name>'.
+//   - histogram: '<name>_bucket_<upper bound>' (cumulative, the last one is '<name>_bucket_inf'),
+//     '<name>_count' and '<name>_sum'.
+//
+// Sums are multiplied by Multiplier (default 1) to keep the fractional part.
+type MetricConfig struct {
+	Name       string    `yaml:"name"`
+	Type       string    `yaml:"type"`
+	Field      string    `yaml:"field"`
+	Buckets    []float64 `yaml:"buckets"`
+	Multiplier float64   `yaml:"multiplier"`
+	MaxValues  int       `yaml:"max_values"`
+}
+
+type metric interface {
+	observe(fields Fields)
+	collect(mx map[string]int64)
+}
+
+// Aggregator aggregates parsed lines into counters, sums and histograms. All the values are totals
+// since the Aggregator creation, so they are suitable for Incremental dimensions.
+type Aggregator struct {
+	metrics []metric
+}
+
+// NewAggregator creates an Aggregator.
+func NewAggregator(cfgs []MetricConfig) (*Aggregator, error) {
+	a := &Aggregator{}
+	seen := make(map[string]bool)
+
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("metrics[%d]: 'name' is not set", i)
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("metric '%s': duplicate name", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		m, err := newMetric(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("metric '%s': %v", cfg.Name, err)
+		}
+		a.metrics = append(a.metrics, m)
+	}
+	return a, nil
+}
+
+// Observe adds the parsed line to all the metrics.
+func (a *Aggregator) Observe(fields Fields) {
+	for _, m := range a.metrics {
+		m.observe(fields)
+	}
+}
+
+// Collect writes the metrics values to mx.
+func (a *Aggregator) Collect(mx map[string]int64) {
+	for _, m := range a.metrics {
+		m.collect(mx)
+	}
+}
+
+func newMetric(cfg MetricConfig) (metric, error) {
+	mul := cfg.Multiplier
+	if mul == 0 {
+		mul = 1
+	}
+	switch cfg.Type {
+	case MetricCounter, "":
+		maxValues := cfg.MaxValues
+		if maxValues <= 0 {
+			maxValues = defaultMaxValues
+		}
+		return &counter{name: cfg.Name, field: cfg.Field, maxValues: maxValues, values: make(map[string]int64)}, nil
+	case MetricSum:
+		if cfg.Field == "" {
+			return nil, errors.New("'field' is not set")
+		}
+		return &sum{name: cfg.Name, field: cfg.Field, mul: mul}, nil
+	case MetricHistogram:
+		if cfg.Field == "" {
+			return nil, errors.New("'field' is not set")
+		}
+		if len(cfg.Buckets) == 0 {
+			return nil, errors.New("'buckets' are not set")
+		}
+		buckets := append([]float64(nil), cfg.Buckets...)
+		sort.Float64s(buckets)
+		return &histogram{
+			name:    cfg.Name,
+			field:   cfg.Field,
+			mul:     mul,
+			buckets: buckets,
+			counts:  make([]int64, len(buckets)),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", cfg.Type)
+	}
+}
+
+type counter struct {
+	name      string
+	field     string
+	maxValues int
+	total     int64
+	values    map[string]int64
+}
+
+func (c *counter) observe(fields Fields) {
+	if c.field == "" {
+		c.total++
+		return
+	}
+	v, ok := fields[c.field]
+	if !ok {
+		return
+	}
+	key := cleanKey(v)
+	if _, ok := c.values[key]; !ok && len(c.values) >= c.maxValues {
+		key = otherValue
+	}
+	c.values[key]++
+}
+
+func (c *counter) collect(mx map[string]int64) {
+	if c.field == "" {
+		mx[c.name] = c.total
+		return
+	}
+	for v, n := range c.values {
+		mx[c.name+"_"+v] = n
+	}
+}
+
+type sum struct {
+	name  string
+	field string
+	mul   float64
+	total float64
+}
+
+func (s *sum) observe(fields Fields) {
+	if v, ok := parseFloat(fields[s.field]); ok {
+		s.total += v
+	}
+}
+
+func (s *sum) collect(mx map[string]int64) {
+	mx[s.name] = int64(math.Round(s.total * s.mul))
+}
+
+type histogram struct {
+	name    string
+	field   string
+	mul     float64
+	buckets []float64
+	counts  []int64 // not cumulative
+	inf     int64
+	count   int64
+	sum     float64
+}
+
+func (h *histogram) observe(fields Fields) {
+	v, ok := parseFloat(fields[h.field])
+	if !ok {
+		return
+	}
+	h.count++
+	h.sum += v
+	idx := sort.SearchFloat64s(h.buckets, v)
+	if idx == len(h.buckets) {
+		h.inf++
+	} else {
+		h.counts[idx]++
+	}
+}
+
+func (h *histogram) collect(mx map[string]int64) {
+	var cumulative int64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		mx[h.name+"_bucket_"+strconv.FormatFloat(bound, 'g', -1, 64)] = cumulative
+	}
+	mx[h.name+"_bucket_inf"] = cumulative + h.inf
+	mx[h.name+"_count"] = h.count
+	mx[h.name+"_sum"] = int64(math.Round(h.sum * h.mul))
+}
+
+func parseFloat(s string) (float64, bool) {
+	if s == "" || s == "-" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func cleanKey(s string) string {
+	if s == "" {
+		return "empty"
+	}
+	return strings.Join(strings.Fields(s), "_")
+}
@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAggregator(t *testing.T) {
+	tests := map[string]struct {
+		cfgs    []MetricConfig
+		wantErr bool
+	}{
+		"valid": {
+			cfgs: []MetricConfig{
+				{Name: "lines"},
+				{Name: "bytes", Type: MetricSum, Field: "bytes"},
+				{Name: "time", Type: MetricHistogram, Field: "time", Buckets: []float64{1}},
+			},
+		},
+		"no name":              {cfgs: []MetricConfig{{Type: MetricCounter}}, wantErr: true},
+		"duplicate name":       {cfgs: []MetricConfig{{Name: "a"}, {Name: "a"}}, wantErr: true},
+		"unknown type":         {cfgs: []MetricConfig{{Name: "a", Type: "gauge"}}, wantErr: true},
+		"sum without field":    {cfgs: []MetricConfig{{Name: "a", Type: MetricSum}}, wantErr: true},
+		"histogram no buckets": {cfgs: []MetricConfig{{Name: "a", Type: MetricHistogram, Field: "a"}}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAggregator(test.cfgs)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAggregator_Collect(t *testing.T) {
+	agg, err := NewAggregator([]MetricConfig{
+		{Name: "lines"},
+		{Name: "status", Field: "status"},
+		{Name: "bytes", Type: MetricSum, Field: "bytes"},
+		{Name: "time", Type: MetricHistogram, Field: "time", Buckets: []float64{1, 0.1}, Multiplier: 1000},
+	})
+	require.NoError(t, err)
+
+	for _, fields := range []Fields{
+		{"status": "200", "bytes": "100", "time": "0.05"},
+		{"status": "200", "bytes": "-", "time": "0.1"},
+		{"status": "404", "bytes": "10", "time": "0.5"},
+		{"status": "", "bytes": "x", "time": "5"},
+		{"bytes": "1.5"},
+	} {
+		agg.Observe(fields)
+	}
+
+	mx := make(map[string]int64)
+	agg.Collect(mx)
+
+	expected := map[string]int64{
+		"lines":           5,
+		"status_200":      2,
+		"status_404":      1,
+		"status_empty":    1,
+		"bytes":           112,
+		"time_bucket_0.1": 2,
+		"time_bucket_1":   3,
+		"time_bucket_inf": 4,
+		"time_count":      4,
+		"time_sum":        5650,
+	}
+	assert.Equal(t, expected, mx)
+}
+
+func TestAggregator_Collect_MaxValues(t *testing.T) {
+	agg, err := NewAggregator([]MetricConfig{{Name: "status", Field: "status", MaxValues: 2}})
+	require.NoError(t, err)
+
+	for _, status := range []string{"200", "404", "500", "200", "503", "404"} {
+		agg.Observe(Fields{"status": status})
+	}
+
+	mx := make(map[string]int64)
+	agg.Collect(mx)
+
+	expected := map[string]int64{
+		"status_200":   2,
+		"status_404":   2,
+		"status_other": 2,
+	}
+	assert.Equal(t, expected, mx)
+}
@@ -0,0 +1,50 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Fields is a parsed log line: field name to field value.
+type Fields map[string]string
+
+// Parser parses a log line.
+type Parser interface {
+	Parse(line []byte) (Fields, error)
+}
+
+const (
+	TypeCSV    = "csv"
+	TypeLTSV   = "ltsv"
+	TypeRegExp = "regexp"
+	TypeJSON   = "json"
+)
+
+// ErrLineMismatch is returned when the line doesn't match the log format.
+var ErrLineMismatch = errors.New("line doesn't match the log format")
+
+// ParserConfig is the log parser configuration.
+// Supported configuration file formats: YAML.
+type ParserConfig struct {
+	LogType string       `yaml:"log_type"`
+	CSV     CSVConfig    `yaml:"csv_config"`
+	LTSV    LTSVConfig   `yaml:"ltsv_config"`
+	RegExp  RegExpConfig `yaml:"regexp_config"`
+	JSON    JSONConfig   `yaml:"json_config"`
+}
+
+// NewParser creates a parser of the configured log type.
+func NewParser(cfg ParserConfig) (Parser, error) {
+	switch cfg.LogType {
+	case TypeCSV:
+		return NewCSVParser(cfg.CSV)
+	case TypeLTSV:
+		return NewLTSVParser(cfg.LTSV)
+	case TypeRegExp:
+		return NewRegExpParser(cfg.RegExp)
+	case TypeJSON:
+		return NewJSONParser(cfg.JSON)
+	default:
+		return nil, fmt.Errorf("invalid log type: '%s'", cfg.LogType)
+	}
+}
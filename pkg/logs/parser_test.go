@@ -0,0 +1,118 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParser(t *testing.T) {
+	tests := map[string]struct {
+		cfg     ParserConfig
+		wantErr bool
+	}{
+		"csv":                       {cfg: ParserConfig{LogType: TypeCSV, CSV: CSVConfig{Fields: []string{"a"}}}},
+		"csv without fields":        {cfg: ParserConfig{LogType: TypeCSV}, wantErr: true},
+		"csv invalid delimiter":     {cfg: ParserConfig{LogType: TypeCSV, CSV: CSVConfig{Delimiter: ";;", Fields: []string{"a"}}}, wantErr: true},
+		"ltsv":                      {cfg: ParserConfig{LogType: TypeLTSV}},
+		"ltsv same delimiters":      {cfg: ParserConfig{LogType: TypeLTSV, LTSV: LTSVConfig{FieldDelimiter: ":"}}, wantErr: true},
+		"regexp":                    {cfg: ParserConfig{LogType: TypeRegExp, RegExp: RegExpConfig{Pattern: `(?P<a>\d+)`}}},
+		"regexp without pattern":    {cfg: ParserConfig{LogType: TypeRegExp}, wantErr: true},
+		"regexp invalid pattern":    {cfg: ParserConfig{LogType: TypeRegExp, RegExp: RegExpConfig{Pattern: `(?P<a>\d+`}}, wantErr: true},
+		"regexp without named subs": {cfg: ParserConfig{LogType: TypeRegExp, RegExp: RegExpConfig{Pattern: `(\d+)`}}, wantErr: true},
+		"json":                      {cfg: ParserConfig{LogType: TypeJSON}},
+		"unknown log type":          {cfg: ParserConfig{LogType: "xml"}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewParser(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, p)
+			}
+		})
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := map[string]struct {
+		cfg     ParserConfig
+		line    string
+		want    Fields
+		wantErr bool
+	}{
+		"csv": {
+			cfg:  ParserConfig{LogType: TypeCSV, CSV: CSVConfig{Delimiter: " ", Fields: []string{"addr", "-", "request", "status"}}},
+			line: `127.0.0.1 - "GET / HTTP/1.1" 200`,
+			want: Fields{"addr": "127.0.0.1", "request": "GET / HTTP/1.1", "status": "200"},
+		},
+		"csv fields mismatch": {
+			cfg:     ParserConfig{LogType: TypeCSV, CSV: CSVConfig{Fields: []string{"a", "b"}}},
+			line:    `1,2,3`,
+			wantErr: true,
+		},
+		"ltsv": {
+			cfg:  ParserConfig{LogType: TypeLTSV},
+			line: "host:127.0.0.1\ttime:[01/Jan/2020:00:00:00 +0000]\tstatus:200",
+			want: Fields{"host": "127.0.0.1", "time": "[01/Jan/2020:00:00:00 +0000]", "status": "200"},
+		},
+		"ltsv no value delimiter": {
+			cfg:     ParserConfig{LogType: TypeLTSV},
+			line:    "host:127.0.0.1\tstatus",
+			wantErr: true,
+		},
+		"regexp": {
+			cfg:  ParserConfig{LogType: TypeRegExp, RegExp: RegExpConfig{Pattern: `^(?P<method>[A-Z]+) (?P<url>\S+) (\d+)$`}},
+			line: "GET /index.html 200",
+			want: Fields{"method": "GET", "url": "/index.html"},
+		},
+		"regexp mismatch": {
+			cfg:     ParserConfig{LogType: TypeRegExp, RegExp: RegExpConfig{Pattern: `^(?P<method>[A-Z]+)$`}},
+			line:    "get",
+			wantErr: true,
+		},
+		"json": {
+			cfg:  ParserConfig{LogType: TypeJSON},
+			line: `{"status":200,"time":0.123456789012,"ok":true,"req":{"method":"GET","tags":["a"]},"user":null}`,
+			want: Fields{
+				"status":     "200",
+				"time":       "0.123456789012",
+				"ok":         "true",
+				"req_method": "GET",
+				"req_tags":   `["a"]`,
+				"user":       "",
+			},
+		},
+		"json custom separator": {
+			cfg:  ParserConfig{LogType: TypeJSON, JSON: JSONConfig{Separator: "."}},
+			line: `{"req":{"method":"GET"}}`,
+			want: Fields{"req.method": "GET"},
+		},
+		"json invalid": {
+			cfg:     ParserConfig{LogType: TypeJSON},
+			line:    `{"status":`,
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewParser(test.cfg)
+			require.NoError(t, err)
+
+			fields, err := p.Parse([]byte(test.line))
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package logs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// position is the persisted read position of a file.
+type position struct {
+	Path   string `json:"path"`
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+func loadPosition(path string) (*position, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pos position
+	if err := json.Unmarshal(bs, &pos); err != nil {
+		return nil, err
+	}
+	return &pos, nil
+}
+
+// savePosition writes the position to a temporary file and renames it, so the position file is never half-written.
+func savePosition(path string, pos position) error {
+	bs, err := json.Marshal(pos)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bs); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
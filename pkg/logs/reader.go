@@ -0,0 +1,209 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Reader tails a file. It follows the file by name: when the file is rotated (renamed or removed
+// and created again) the rest of the old file is read first, then the new file is read from the beginning.
+// If the file is truncated it is read from the beginning as well.
+//
+// On the first start (no position file, or the position doesn't belong to the file) reading starts from the end
+// of the file. The read offset is persisted to the position file (if set) on SavePosition and Close.
+type Reader struct {
+	path    string
+	posFile string
+
+	file    *os.File
+	rd      *bufio.Reader
+	offset  int64
+	partial []byte
+}
+
+// Open opens the file and restores the read position.
+func Open(path, posFile string) (*Reader, error) {
+	r := &Reader{
+		path:    path,
+		posFile: posFile,
+	}
+	if err := r.open(r.restoredOffset); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the path of the file.
+func (r *Reader) Path() string { return r.path }
+
+// Offset returns the read offset in the current file.
+func (r *Reader) Offset() int64 { return r.offset }
+
+// ReadLines reads all the complete lines written since the last call and calls fn for every line.
+// The line passed to fn is only valid until fn returns.
+func (r *Reader) ReadLines(fn func(line []byte)) error {
+	if r.file == nil {
+		if err := r.open(func(os.FileInfo) int64 { return 0 }); err != nil {
+			return err
+		}
+	}
+
+	if err := r.handleTruncate(); err != nil {
+		return err
+	}
+	if err := r.readLines(fn); err != nil {
+		return err
+	}
+
+	rotated, err := r.isRotated()
+	if err != nil || !rotated {
+		return err
+	}
+
+	// the old file is read to the end, flush the last line if it is not terminated with a newline.
+	if len(r.partial) > 0 {
+		fn(r.partial)
+		r.partial = r.partial[:0]
+	}
+	_ = r.file.Close()
+	r.file = nil
+
+	if err := r.open(func(os.FileInfo) int64 { return 0 }); err != nil {
+		return err
+	}
+	return r.readLines(fn)
+}
+
+// SavePosition persists the current read offset. It is no-op if the position file is not set.
+func (r *Reader) SavePosition() error {
+	if r.posFile == "" || r.file == nil {
+		return nil
+	}
+	fi, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+	// the partial line will be read again.
+	pos := position{Path: r.path, Inode: inode(fi), Offset: r.offset - int64(len(r.partial))}
+	return savePosition(r.posFile, pos)
+}
+
+// Close saves the position and closes the file.
+func (r *Reader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.SavePosition()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file = nil
+	return err
+}
+
+func (r *Reader) open(offsetFunc func(fi os.FileInfo) int64) error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		_ = f.Close()
+		return fmt.Errorf("'%s' is not a regular file", r.path)
+	}
+
+	offset := offsetFunc(fi)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	r.file = f
+	r.offset = offset
+	r.rd = bufio.NewReader(f)
+	r.partial = r.partial[:0]
+	return nil
+}
+
+// restoredOffset returns the persisted offset if the position belongs to the file, otherwise the file size.
+func (r *Reader) restoredOffset(fi os.FileInfo) int64 {
+	if r.posFile == "" {
+		return fi.Size()
+	}
+	pos, err := loadPosition(r.posFile)
+	if err != nil || pos.Path != r.path {
+		return fi.Size()
+	}
+	if pos.Inode != inode(fi) {
+		// the file was rotated while we were not running, all of it is new.
+		return 0
+	}
+	if pos.Offset > fi.Size() {
+		return 0
+	}
+	return pos.Offset
+}
+
+func (r *Reader) handleTruncate() error {
+	fi, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() >= r.offset {
+		return nil
+	}
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r.offset = 0
+	r.rd.Reset(r.file)
+	r.partial = r.partial[:0]
+	return nil
+}
+
+func (r *Reader) readLines(fn func(line []byte)) error {
+	for {
+		bs, err := r.rd.ReadBytes('\n')
+		r.offset += int64(len(bs))
+
+		if err != nil {
+			r.partial = append(r.partial, bs...)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if len(r.partial) > 0 {
+			bs = append(r.partial, bs...)
+			r.partial = r.partial[:0]
+		}
+		if line := bytes.TrimRight(bs, "\r\n"); len(line) > 0 {
+			fn(line)
+		}
+	}
+}
+
+func (r *Reader) isRotated() (bool, error) {
+	fi, err := os.Stat(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// the file is moved, but the new one is not created yet.
+			return false, nil
+		}
+		return false, err
+	}
+	cur, err := r.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(fi, cur), nil
+}
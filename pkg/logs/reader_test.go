@@ -0,0 +1,209 @@
+package logs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_StartsAtTheEndOnFirstRun(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "app.log")
+	writeFile(t, path, "old1\nold2\n")
+
+	r, err := Open(path, filepath.Join(dir, "pos.json"))
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+
+	assert.Empty(t, readLines(t, r))
+	appendFile(t, path, "new1\n")
+	assert.Equal(t, []string{"new1"}, readLines(t, r))
+}
+
+func TestOpen_NotExistingFile(t *testing.T) {
+	_, err := Open("testdata/not-exist.log", "")
+	assert.Error(t, err)
+}
+
+func TestReader_ReadLines(t *testing.T) {
+	tests := map[string]struct {
+		steps []func(t *testing.T, path string)
+		want  [][]string
+	}{
+		"append": {
+			steps: []func(*testing.T, string){
+				func(t *testing.T, path string) { appendFile(t, path, "line1\nline2\n") },
+				func(t *testing.T, path string) {},
+				func(t *testing.T, path string) { appendFile(t, path, "line3\r\n\n") },
+			},
+			want: [][]string{{"line1", "line2"}, nil, {"line3"}},
+		},
+		"partial line": {
+			steps: []func(*testing.T, string){
+				func(t *testing.T, path string) { appendFile(t, path, "line1\nli") },
+				func(t *testing.T, path string) { appendFile(t, path, "ne2") },
+				func(t *testing.T, path string) { appendFile(t, path, "\n") },
+			},
+			want: [][]string{{"line1"}, nil, {"line2"}},
+		},
+		"truncate": {
+			steps: []func(*testing.T, string){
+				func(t *testing.T, path string) { appendFile(t, path, "line1\nline2\n") },
+				func(t *testing.T, path string) { writeFile(t, path, "line3\n") },
+			},
+			want: [][]string{{"line1", "line2"}, {"line3"}},
+		},
+		"rename rotation": {
+			steps: []func(*testing.T, string){
+				func(t *testing.T, path string) { appendFile(t, path, "line1\n") },
+				func(t *testing.T, path string) {
+					appendFile(t, path, "line2\nline3")
+					require.NoError(t, os.Rename(path, path+".1"))
+					writeFile(t, path, "line4\n")
+				},
+				func(t *testing.T, path string) { appendFile(t, path, "line5\n") },
+			},
+			want: [][]string{{"line1"}, {"line2", "line3", "line4"}, {"line5"}},
+		},
+		"rename rotation, new file not created yet": {
+			steps: []func(*testing.T, string){
+				func(t *testing.T, path string) {
+					appendFile(t, path, "line1\n")
+					require.NoError(t, os.Rename(path, path+".1"))
+				},
+				func(t *testing.T, path string) { writeFile(t, path, "line2\n") },
+			},
+			want: [][]string{{"line1"}, {"line2"}},
+		},
+		"remove rotation": {
+			steps: []func(*testing.T, string){
+				func(t *testing.T, path string) { appendFile(t, path, "line1\n") },
+				func(t *testing.T, path string) {
+					require.NoError(t, os.Remove(path))
+					writeFile(t, path, "line2\n")
+				},
+			},
+			want: [][]string{{"line1"}, {"line2"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "app.log")
+			writeFile(t, path, "")
+
+			r, err := Open(path, "")
+			require.NoError(t, err)
+			defer func() { _ = r.Close() }()
+
+			for i, step := range test.steps {
+				step(t, path)
+				assert.Equalf(t, test.want[i], readLines(t, r), "step %d", i+1)
+			}
+		})
+	}
+}
+
+func TestReader_PositionPersistence(t *testing.T) {
+	tests := map[string]struct {
+		beforeReopen func(t *testing.T, path string)
+		want         []string
+	}{
+		"continues from the saved offset": {
+			beforeReopen: func(t *testing.T, path string) { appendFile(t, path, "\nline3\n") },
+			want:         []string{"part", "line3"},
+		},
+		"re-reads the partial line": {
+			beforeReopen: func(t *testing.T, path string) { appendFile(t, path, "-end\n") },
+			want:         []string{"part-end"},
+		},
+		"rotated while not running": {
+			beforeReopen: func(t *testing.T, path string) {
+				require.NoError(t, os.Rename(path, path+".1"))
+				writeFile(t, path, "line4\n")
+			},
+			want: []string{"line4"},
+		},
+		"truncated while not running": {
+			beforeReopen: func(t *testing.T, path string) {
+				f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
+				require.NoError(t, err)
+				_, err = f.WriteString("l5\n")
+				require.NoError(t, err)
+				require.NoError(t, f.Close())
+			},
+			want: []string{"l5"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "app.log")
+			posFile := filepath.Join(dir, "pos.json")
+			writeFile(t, path, "")
+
+			r, err := Open(path, posFile)
+			require.NoError(t, err)
+			appendFile(t, path, "line1\nline2\npart")
+			assert.Equal(t, []string{"line1", "line2"}, readLines(t, r))
+			require.NoError(t, r.Close())
+
+			test.beforeReopen(t, path)
+
+			r, err = Open(path, posFile)
+			require.NoError(t, err)
+			defer func() { _ = r.Close() }()
+			assert.Equal(t, test.want, readLines(t, r))
+		})
+	}
+}
+
+func TestReader_PositionOfAnotherFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	posFile := filepath.Join(dir, "pos.json")
+	path1, path2 := filepath.Join(dir, "app1.log"), filepath.Join(dir, "app2.log")
+	writeFile(t, path1, "")
+	writeFile(t, path2, "line1\n")
+
+	r, err := Open(path1, posFile)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	r, err = Open(path2, posFile)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+	assert.Equal(t, int64(len("line1\n")), r.Offset())
+}
+
+func readLines(t *testing.T, r *Reader) (lines []string) {
+	require.NoError(t, r.ReadLines(func(line []byte) { lines = append(lines, string(line)) }))
+	return lines
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-logs")
+	require.NoError(t, err)
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
+
+func writeFile(t *testing.T, path, data string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+}
+
+func appendFile(t *testing.T, path, data string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+}
@@ -0,0 +1,56 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// RegExpConfig is the regular expression parser configuration.
+// The pattern must have named subexpressions, their names are the field names.
+type RegExpConfig struct {
+	Pattern string `yaml:"pattern"`
+}
+
+// RegExpParser parses lines with a regular expression.
+type RegExpParser struct {
+	re *regexp.Regexp
+}
+
+// NewRegExpParser creates a RegExpParser.
+func NewRegExpParser(cfg RegExpConfig) (*RegExpParser, error) {
+	if cfg.Pattern == "" {
+		return nil, errors.New("regexp parser: 'pattern' is not set")
+	}
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("regexp parser: %v", err)
+	}
+	var named bool
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			named = true
+			break
+		}
+	}
+	if !named {
+		return nil, errors.New("regexp parser: pattern has no named subexpressions")
+	}
+	return &RegExpParser{re: re}, nil
+}
+
+// Parse parses the line.
+func (p *RegExpParser) Parse(line []byte) (Fields, error) {
+	match := p.re.FindSubmatch(line)
+	if match == nil {
+		return nil, ErrLineMismatch
+	}
+	fields := make(Fields)
+	for i, name := range p.re.SubexpNames() {
+		if name == "" || i >= len(match) {
+			continue
+		}
+		fields[name] = string(match[i])
+	}
+	return fields, nil
+}
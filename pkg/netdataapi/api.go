@@ -48,6 +48,18 @@ func (a *API) DIMENSION(
 	return err
 }
 
+// CLABEL add a label to the chart just created. The labels are applied on CLABEL_COMMIT.
+func (a *API) CLABEL(key string, value string, source int) error {
+	_, err := fmt.Fprintf(a, "CLABEL '%s' '%s' '%d'\n", key, value, source)
+	return err
+}
+
+// CLABEL_COMMIT apply the labels added by CLABEL to the chart just created.
+func (a *API) CLABEL_COMMIT() error {
+	_, err := fmt.Fprintf(a, "CLABEL_COMMIT\n")
+	return err
+}
+
 // BEGIN initialize data collection for a chart.
 func (a *API) BEGIN(typeID string, ID string, msSince int) (err error) {
 	if msSince > 0 {
@@ -54,6 +54,32 @@ func TestAPI_DIMENSION(t *testing.T) {
 	)
 }
 
+func TestAPI_CLABEL(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.CLABEL("key", "value", 2)
+
+	assert.Equal(
+		t,
+		"CLABEL 'key' 'value' '2'\n",
+		b.String(),
+	)
+}
+
+func TestAPI_CLABEL_COMMIT(t *testing.T) {
+	b := &bytes.Buffer{}
+	netdataAPI := API{Writer: b}
+
+	_ = netdataAPI.CLABEL_COMMIT()
+
+	assert.Equal(
+		t,
+		"CLABEL_COMMIT\n",
+		b.String(),
+	)
+}
+
 func TestAPI_BEGIN(t *testing.T) {
 	b := &bytes.Buffer{}
 	netdataAPI := API{Writer: b}
@@ -0,0 +1,94 @@
+package prometheus
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// Label is a key/value pair of a metric label.
+	Label struct {
+		Name  string
+		Value string
+	}
+	// Labels is a sorted by name set of labels.
+	Labels []Label
+)
+
+func (ls Labels) Len() int           { return len(ls) }
+func (ls Labels) Swap(i, j int)      { ls[i], ls[j] = ls[j], ls[i] }
+func (ls Labels) Less(i, j int) bool { return ls[i].Name < ls[j].Name }
+
+// Get returns the value for the label with the given name.
+// Returns an empty string if the label doesn't exist.
+func (ls Labels) Get(name string) string {
+	v, _ := ls.Lookup(name)
+	return v
+}
+
+// Lookup returns the value for the label with the given name and whether the label exists.
+func (ls Labels) Lookup(name string) (string, bool) {
+	for _, l := range ls {
+		if l.Name == name {
+			return l.Value, true
+		}
+	}
+	return "", false
+}
+
+// Has returns true if the label with the given name exists.
+func (ls Labels) Has(name string) bool {
+	_, ok := ls.Lookup(name)
+	return ok
+}
+
+// Copy returns a copy of the labels.
+func (ls Labels) Copy() Labels {
+	res := make(Labels, len(ls))
+	copy(res, ls)
+	return res
+}
+
+// Without returns a copy of the labels without the labels with the given names.
+func (ls Labels) Without(names ...string) Labels {
+	res := make(Labels, 0, len(ls))
+LOOP:
+	for _, l := range ls {
+		for _, name := range names {
+			if l.Name == name {
+				continue LOOP
+			}
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+// String returns the labels in the Prometheus exposition format, e.g. '{job="node",instance="host"}'.
+func (ls Labels) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, l := range ls {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(l.Value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (ls Labels) sort() {
+	if !sort.IsSorted(ls) {
+		sort.Sort(ls)
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
@@ -0,0 +1,199 @@
+package prometheus
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MetricType is a metric family type.
+type MetricType string
+
+const (
+	Unknown   MetricType = "unknown"
+	Counter   MetricType = "counter"
+	Gauge     MetricType = "gauge"
+	Histogram MetricType = "histogram"
+	Summary   MetricType = "summary"
+)
+
+func (t MetricType) String() string { return string(t) }
+
+type (
+	// MetricFamilies is a collection of metric families, the key is the metric family name.
+	MetricFamilies map[string]*MetricFamily
+
+	// MetricFamily is a set of metrics with the same name, type and help.
+	MetricFamily struct {
+		Name    string
+		Help    string
+		Type    MetricType
+		Metrics []Metric
+	}
+
+	// Metric is a single metric of a family, uniquely identified by its labels.
+	// Value is set for counters, gauges and metrics of unknown type.
+	// Histogram and Summary are set for the histogram and summary families respectively.
+	Metric struct {
+		Labels    Labels
+		Value     float64
+		Histogram *HistogramValue
+		Summary   *SummaryValue
+	}
+
+	// HistogramValue is a histogram metric value. Buckets are cumulative and sorted by the upper bound.
+	HistogramValue struct {
+		Count   float64
+		Sum     float64
+		Buckets []Bucket
+	}
+	// Bucket is a histogram bucket.
+	Bucket struct {
+		UpperBound      float64
+		CumulativeCount float64
+	}
+
+	// SummaryValue is a summary metric value. Quantiles are sorted by the quantile.
+	SummaryValue struct {
+		Count     float64
+		Sum       float64
+		Quantiles []Quantile
+	}
+	// Quantile is a summary quantile.
+	Quantile struct {
+		Quantile float64
+		Value    float64
+	}
+)
+
+// Get returns the metric family with the given name, or nil.
+func (mfs MetricFamilies) Get(name string) *MetricFamily {
+	return mfs[name]
+}
+
+// Names returns sorted metric family names.
+func (mfs MetricFamilies) Names() []string {
+	names := make([]string, 0, len(mfs))
+	for name := range mfs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Len returns the number of metric families.
+func (mfs MetricFamilies) Len() int { return len(mfs) }
+
+// Filter returns metric families with metrics that match the selector.
+// Families without matched metrics are dropped.
+func (mfs MetricFamilies) Filter(sel Selector) MetricFamilies {
+	if sel == nil {
+		return mfs
+	}
+	res := make(MetricFamilies)
+	for name, mf := range mfs {
+		var metrics []Metric
+		for _, m := range mf.Metrics {
+			if sel.Matches(name, m.Labels) {
+				metrics = append(metrics, m)
+			}
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		cp := *mf
+		cp.Metrics = metrics
+		res[name] = &cp
+	}
+	return res
+}
+
+// Series flattens metric families into a series of samples sorted by the sample name and labels.
+// Histograms are flattened into '_bucket' (with 'le' label), '_sum' and '_count' samples,
+// summaries into the samples with the 'quantile' label, '_sum' and '_count' samples.
+func (mfs MetricFamilies) Series() Series {
+	var series Series
+	for name, mf := range mfs {
+		for _, m := range mf.Metrics {
+			switch {
+			case m.Histogram != nil:
+				for _, b := range m.Histogram.Buckets {
+					lbs := append(m.Labels.Copy(), Label{Name: "le", Value: formatFloat(b.UpperBound)})
+					lbs.sort()
+					series = append(series, Sample{Name: name + "_bucket", Labels: lbs, Value: b.CumulativeCount})
+				}
+				series = append(series,
+					Sample{Name: name + "_sum", Labels: m.Labels, Value: m.Histogram.Sum},
+					Sample{Name: name + "_count", Labels: m.Labels, Value: m.Histogram.Count},
+				)
+			case m.Summary != nil:
+				for _, q := range m.Summary.Quantiles {
+					lbs := append(m.Labels.Copy(), Label{Name: "quantile", Value: formatFloat(q.Quantile)})
+					lbs.sort()
+					series = append(series, Sample{Name: name, Labels: lbs, Value: q.Value})
+				}
+				series = append(series,
+					Sample{Name: name + "_sum", Labels: m.Labels, Value: m.Summary.Sum},
+					Sample{Name: name + "_count", Labels: m.Labels, Value: m.Summary.Count},
+				)
+			default:
+				series = append(series, Sample{Name: name, Labels: m.Labels, Value: m.Value})
+			}
+		}
+	}
+	sort.Sort(series)
+	return series
+}
+
+type (
+	// Sample is a single flattened value.
+	Sample struct {
+		Name   string
+		Labels Labels
+		Value  float64
+	}
+	// Series is a collection of samples.
+	Series []Sample
+)
+
+func (s Series) Len() int      { return len(s) }
+func (s Series) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s Series) Less(i, j int) bool {
+	if s[i].Name != s[j].Name {
+		return s[i].Name < s[j].Name
+	}
+	return s[i].Labels.String() < s[j].Labels.String()
+}
+
+// ID returns the sample identifier that is suitable to be used as a chart dimension ID:
+// the name followed by the label values separated by '_', whitespaces are replaced with '_'.
+func (s Sample) ID() string {
+	var b strings.Builder
+	b.WriteString(s.Name)
+	for _, l := range s.Labels {
+		b.WriteByte('_')
+		b.WriteString(l.Value)
+	}
+	return strings.Join(strings.Fields(b.String()), "_")
+}
+
+// Map converts the series to the map that Module.Collect returns.
+// Keys are the sample IDs, values are multiplied by mul and rounded. NaN and Inf values are skipped.
+func (s Series) Map(mul float64) map[string]int64 {
+	if mul == 0 {
+		mul = 1
+	}
+	mx := make(map[string]int64, len(s))
+	for _, sample := range s {
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue
+		}
+		mx[sample.ID()] = int64(math.Round(sample.Value * mul))
+	}
+	return mx
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
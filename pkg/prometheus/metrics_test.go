@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSelector func(name string, lbs Labels) bool
+
+func (s testSelector) Matches(name string, lbs Labels) bool { return s(name, lbs) }
+
+func TestMetricFamilies_Filter(t *testing.T) {
+	mfs, err := ParseBytes(testData)
+	require.NoError(t, err)
+
+	filtered := mfs.Filter(testSelector(func(name string, lbs Labels) bool {
+		return name == "http_requests_total" && lbs.Get("code") == "400"
+	}))
+
+	assert.Equal(t, []string{"http_requests_total"}, filtered.Names())
+	assert.Len(t, filtered.Get("http_requests_total").Metrics, 1)
+	assert.Len(t, mfs.Get("http_requests_total").Metrics, 2)
+	assert.Equal(t, mfs, mfs.Filter(nil))
+}
+
+func TestMetricFamilies_Series(t *testing.T) {
+	mfs, err := ParseBytes(testData)
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range mfs.Series() {
+		names = append(names, s.ID())
+	}
+
+	assert.Equal(t, []string{
+		"go_goroutines",
+		"http_request_duration_seconds_bucket_+Inf",
+		"http_request_duration_seconds_bucket_0.05",
+		"http_request_duration_seconds_bucket_0.1",
+		"http_request_duration_seconds_count",
+		"http_request_duration_seconds_sum",
+		"http_requests_total_200_post",
+		"http_requests_total_400_post",
+		"rpc_duration_seconds_0.5",
+		"rpc_duration_seconds_0.99",
+		"rpc_duration_seconds_count",
+		"rpc_duration_seconds_sum",
+		`untyped_metric_with_"quotes",_comma_and_spaces`,
+	}, names)
+}
+
+func TestSeries_Map(t *testing.T) {
+	series := Series{
+		{Name: "a", Value: 1.5},
+		{Name: "b", Labels: Labels{{"l", "v"}}, Value: 2},
+		{Name: "c", Value: math.Inf(1)},
+	}
+
+	assert.Equal(t, map[string]int64{"a": 2, "b_v": 2}, series.Map(1))
+	assert.Equal(t, map[string]int64{"a": 1500, "b_v": 2000}, series.Map(1000))
+}
+
+func TestLabels(t *testing.T) {
+	lbs := Labels{{"a", "1"}, {"b", "2\"\n"}}
+
+	assert.Equal(t, "1", lbs.Get("a"))
+	assert.Equal(t, "", lbs.Get("c"))
+	assert.True(t, lbs.Has("b"))
+	assert.False(t, lbs.Has("c"))
+	assert.Equal(t, Labels{{"b", "2\"\n"}}, lbs.Without("a"))
+	assert.Equal(t, `{a="1",b="2\"\n"}`, lbs.String())
+}
@@ -0,0 +1,346 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parse parses the Prometheus text exposition format (version 0.0.4) and the OpenMetrics text format
+// into metric families. Samples without a TYPE line are considered to be of Unknown type.
+func Parse(r io.Reader) (MetricFamilies, error) {
+	p := newParser()
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var num int
+	for sc.Scan() {
+		num++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if line[0] == '#' {
+			if p.parseComment(line) {
+				break
+			}
+			continue
+		}
+		if err := p.parseSample(line); err != nil {
+			return nil, fmt.Errorf("line %d: %v", num, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return p.finish(), nil
+}
+
+// ParseBytes is a shortcut for Parse(bytes.NewReader(bs)).
+func ParseBytes(bs []byte) (MetricFamilies, error) {
+	return Parse(bytes.NewReader(bs))
+}
+
+type parser struct {
+	mfs   MetricFamilies
+	types map[string]MetricType
+	helps map[string]string
+	// index maps a metric family name and the metric labels (without 'le' and 'quantile') to the metric index.
+	index map[string]map[string]int
+}
+
+func newParser() *parser {
+	return &parser{
+		mfs:   make(MetricFamilies),
+		types: make(map[string]MetricType),
+		helps: make(map[string]string),
+		index: make(map[string]map[string]int),
+	}
+}
+
+// parseComment handles '# HELP', '# TYPE' and '# EOF' lines, it returns true on EOF.
+func (p *parser) parseComment(line string) (eof bool) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) == 2 && parts[1] == "EOF" {
+		return true
+	}
+	if len(parts) < 3 {
+		return false
+	}
+
+	switch name := parts[2]; parts[1] {
+	case "HELP":
+		if len(parts) == 4 {
+			p.helps[name] = unescapeHelp(parts[3])
+		}
+	case "TYPE":
+		if len(parts) == 4 {
+			p.types[name] = parseMetricType(parts[3])
+		}
+	}
+	return false
+}
+
+func (p *parser) parseSample(line string) error {
+	name, lbs, value, err := parseSampleLine(line)
+	if err != nil {
+		return err
+	}
+
+	famName, suffix := p.familyName(name)
+	typ := p.types[famName]
+	if typ == "" {
+		typ = Unknown
+	}
+	if suffix == "_created" {
+		return nil
+	}
+
+	switch typ {
+	case Histogram:
+		m := p.metric(famName, typ, lbs.Without("le"))
+		if m.Histogram == nil {
+			m.Histogram = &HistogramValue{}
+		}
+		switch suffix {
+		case "_sum":
+			m.Histogram.Sum = value
+		case "_count":
+			m.Histogram.Count = value
+		case "_bucket":
+			le, ok := lbs.Lookup("le")
+			if !ok {
+				return fmt.Errorf("histogram '%s' bucket without 'le' label", famName)
+			}
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				return fmt.Errorf("histogram '%s' bucket 'le' label: %v", famName, err)
+			}
+			m.Histogram.Buckets = append(m.Histogram.Buckets, Bucket{UpperBound: bound, CumulativeCount: value})
+		}
+	case Summary:
+		m := p.metric(famName, typ, lbs.Without("quantile"))
+		if m.Summary == nil {
+			m.Summary = &SummaryValue{}
+		}
+		switch suffix {
+		case "_sum":
+			m.Summary.Sum = value
+		case "_count":
+			m.Summary.Count = value
+		default:
+			v, ok := lbs.Lookup("quantile")
+			if !ok {
+				return fmt.Errorf("summary '%s' sample without 'quantile' label", famName)
+			}
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("summary '%s' 'quantile' label: %v", famName, err)
+			}
+			m.Summary.Quantiles = append(m.Summary.Quantiles, Quantile{Quantile: q, Value: value})
+		}
+	default:
+		mf := p.family(famName, typ)
+		mf.Metrics = append(mf.Metrics, Metric{Labels: lbs, Value: value})
+	}
+	return nil
+}
+
+// familyName returns the metric family name of the sample and the sample name suffix
+// ('_bucket', '_sum', '_count', '_total', '_created') if the family is known and the suffix fits its type.
+func (p *parser) familyName(name string) (string, string) {
+	if _, ok := p.types[name]; ok {
+		return name, ""
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count", "_total", "_created"} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		base := strings.TrimSuffix(name, suffix)
+		typ, ok := p.types[base]
+		if !ok {
+			continue
+		}
+		switch {
+		case typ == Histogram && (suffix == "_bucket" || suffix == "_sum" || suffix == "_count" || suffix == "_created"),
+			typ == Summary && (suffix == "_sum" || suffix == "_count" || suffix == "_created"),
+			typ == Counter && (suffix == "_total" || suffix == "_created"):
+			return base, suffix
+		}
+	}
+	return name, ""
+}
+
+func (p *parser) family(name string, typ MetricType) *MetricFamily {
+	mf, ok := p.mfs[name]
+	if !ok {
+		mf = &MetricFamily{Name: name, Type: typ}
+		p.mfs[name] = mf
+	}
+	return mf
+}
+
+func (p *parser) metric(famName string, typ MetricType, lbs Labels) *Metric {
+	mf := p.family(famName, typ)
+
+	idx, ok := p.index[famName]
+	if !ok {
+		idx = make(map[string]int)
+		p.index[famName] = idx
+	}
+
+	key := lbs.String()
+	i, ok := idx[key]
+	if !ok {
+		mf.Metrics = append(mf.Metrics, Metric{Labels: lbs})
+		i = len(mf.Metrics) - 1
+		idx[key] = i
+	}
+	return &mf.Metrics[i]
+}
+
+func (p *parser) finish() MetricFamilies {
+	for name, mf := range p.mfs {
+		mf.Help = p.helps[name]
+		for _, m := range mf.Metrics {
+			if m.Histogram != nil {
+				sort.Slice(m.Histogram.Buckets, func(i, j int) bool {
+					return m.Histogram.Buckets[i].UpperBound < m.Histogram.Buckets[j].UpperBound
+				})
+			}
+			if m.Summary != nil {
+				sort.Slice(m.Summary.Quantiles, func(i, j int) bool {
+					return m.Summary.Quantiles[i].Quantile < m.Summary.Quantiles[j].Quantile
+				})
+			}
+		}
+	}
+	return p.mfs
+}
+
+func parseSampleLine(line string) (name string, lbs Labels, value float64, err error) {
+	i := 0
+	for i < len(line) && isNameChar(line[i], i == 0) {
+		i++
+	}
+	if i == 0 {
+		return "", nil, 0, fmt.Errorf("invalid metric name in '%s'", line)
+	}
+	name = line[:i]
+	line = line[i:]
+
+	if strings.HasPrefix(line, "{") {
+		var n int
+		if lbs, n, err = parseLabels(line); err != nil {
+			return "", nil, 0, fmt.Errorf("metric '%s': %v", name, err)
+		}
+		line = line[n:]
+	}
+
+	// the value is followed by an optional timestamp and an optional OpenMetrics exemplar.
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil, 0, fmt.Errorf("metric '%s': no value", name)
+	}
+	if value, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return "", nil, 0, fmt.Errorf("metric '%s': %v", name, err)
+	}
+	lbs.sort()
+	return name, lbs, value, nil
+}
+
+// parseLabels parses '{name="value",...}' and returns the labels and the number of consumed bytes.
+func parseLabels(s string) (Labels, int, error) {
+	var lbs Labels
+	i := 1 // skip '{'
+
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, errors.New("unterminated label set")
+		}
+		if s[i] == '}' {
+			return lbs, i + 1, nil
+		}
+
+		start := i
+		for i < len(s) && isNameChar(s[i], i == start) && s[i] != ':' {
+			i++
+		}
+		if i == start {
+			return nil, 0, fmt.Errorf("invalid label name at position %d", i)
+		}
+		lname := s[start:i]
+
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i+1 >= len(s) || s[i] != '=' || s[i+1] != '"' {
+			return nil, 0, fmt.Errorf("label '%s': expected '=\"'", lname)
+		}
+		i += 2
+
+		var b strings.Builder
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] != '\\' || i+1 >= len(s) {
+				b.WriteByte(s[i])
+				continue
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case '\\', '"':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		}
+		if i >= len(s) {
+			return nil, 0, fmt.Errorf("label '%s': unterminated value", lname)
+		}
+		i++ // skip closing '"'
+
+		lbs = append(lbs, Label{Name: lname, Value: b.String()})
+	}
+}
+
+func parseMetricType(s string) MetricType {
+	switch MetricType(strings.ToLower(strings.TrimSpace(s))) {
+	case Counter:
+		return Counter
+	case Gauge, "gaugehistogram", "stateset", "info":
+		return Gauge
+	case Histogram:
+		return Histogram
+	case Summary:
+		return Summary
+	default:
+		return Unknown
+	}
+}
+
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
+
+var helpUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+
+func unescapeHelp(s string) string {
+	return helpUnescaper.Replace(s)
+}
@@ -0,0 +1,127 @@
+package prometheus
+
+import (
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testData, _            = ioutil.ReadFile("testdata/testdata.txt")
+	testDataOpenMetrics, _ = ioutil.ReadFile("testdata/testdata.openmetrics.txt")
+)
+
+func Test_testDataIsValid(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"testData":            testData,
+		"testDataOpenMetrics": testDataOpenMetrics,
+	} {
+		require.NotNilf(t, data, name)
+	}
+}
+
+func TestParse(t *testing.T) {
+	mfs, err := ParseBytes(testData)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"go_goroutines",
+		"http_request_duration_seconds",
+		"http_requests_total",
+		"rpc_duration_seconds",
+		"untyped_metric",
+	}, mfs.Names())
+
+	gauge := mfs.Get("go_goroutines")
+	assert.Equal(t, Gauge, gauge.Type)
+	assert.Equal(t, "Number of goroutines that currently exist.", gauge.Help)
+	assert.Equal(t, []Metric{{Value: 13}}, gauge.Metrics)
+
+	counter := mfs.Get("http_requests_total")
+	assert.Equal(t, Counter, counter.Type)
+	assert.Equal(t, []Metric{
+		{Labels: Labels{{"code", "200"}, {"method", "post"}}, Value: 1027},
+		{Labels: Labels{{"code", "400"}, {"method", "post"}}, Value: 3},
+	}, counter.Metrics)
+
+	histogram := mfs.Get("http_request_duration_seconds")
+	assert.Equal(t, Histogram, histogram.Type)
+	require.Len(t, histogram.Metrics, 1)
+	assert.Equal(t, &HistogramValue{
+		Count: 144320,
+		Sum:   53423,
+		Buckets: []Bucket{
+			{UpperBound: 0.05, CumulativeCount: 24054},
+			{UpperBound: 0.1, CumulativeCount: 33444},
+			{UpperBound: math.Inf(1), CumulativeCount: 144320},
+		},
+	}, histogram.Metrics[0].Histogram)
+
+	summary := mfs.Get("rpc_duration_seconds")
+	assert.Equal(t, Summary, summary.Type)
+	require.Len(t, summary.Metrics, 1)
+	assert.Equal(t, &SummaryValue{
+		Count: 2693,
+		Sum:   1.7560473e+07,
+		Quantiles: []Quantile{
+			{Quantile: 0.5, Value: 4773},
+			{Quantile: 0.99, Value: 76656},
+		},
+	}, summary.Metrics[0].Summary)
+
+	untyped := mfs.Get("untyped_metric")
+	assert.Equal(t, Unknown, untyped.Type)
+	assert.Equal(t, []Metric{
+		{Labels: Labels{{"label", `with "quotes", comma and spaces`}}, Value: -1},
+	}, untyped.Metrics)
+}
+
+func TestParse_OpenMetrics(t *testing.T) {
+	mfs, err := ParseBytes(testDataOpenMetrics)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"acme_http_router_request_seconds", "foo", "go_memstats_alloc_bytes"}, mfs.Names())
+
+	summary := mfs.Get("acme_http_router_request_seconds")
+	require.Len(t, summary.Metrics, 1)
+	assert.Equal(t, &SummaryValue{Count: 807283, Sum: 9036.32}, summary.Metrics[0].Summary)
+
+	counter := mfs.Get("go_memstats_alloc_bytes")
+	assert.Equal(t, Counter, counter.Type)
+	assert.Equal(t, []Metric{{Value: 1.546544e+06}}, counter.Metrics)
+
+	histogram := mfs.Get("foo")
+	require.Len(t, histogram.Metrics, 1)
+	assert.Equal(t, float64(1), histogram.Metrics[0].Histogram.Count)
+	assert.Len(t, histogram.Metrics[0].Histogram.Buckets, 2)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no value":                  "metric_name\n",
+		"invalid value":             "metric_name abc\n",
+		"invalid name":              "{label=\"value\"} 1\n",
+		"unterminated label set":    "metric_name{label=\"value\" 1\n",
+		"unquoted label value":      "metric_name{label=value} 1\n",
+		"histogram bucket no le":    "# TYPE h histogram\nh_bucket 1\n",
+		"summary without quantile":  "# TYPE s summary\ns 1\n",
+		"histogram bucket le isnan": "# TYPE h histogram\nh_bucket{le=\"a\"} 1\n",
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseBytes(testData)
+	}
+}
@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+const acceptHeader = `application/openmetrics-text; version=0.0.1,text/plain;version=0.0.4;q=0.5,*/*;q=0.1`
+
+// Prometheus scrapes and parses a Prometheus endpoint.
+type Prometheus interface {
+	// Scrape scrapes the endpoint and returns parsed metric families.
+	Scrape() (MetricFamilies, error)
+}
+
+type prometheus struct {
+	client   *http.Client
+	request  web.Request
+	selector Selector
+}
+
+// New creates a new Prometheus. The selector is optional, metric families are filtered if it is set.
+func New(client *http.Client, request web.Request, sel Selector) Prometheus {
+	return &prometheus{
+		client:   client,
+		request:  request,
+		selector: sel,
+	}
+}
+
+func (p *prometheus) Scrape() (MetricFamilies, error) {
+	req, err := web.NewHTTPRequest(p.request)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating request to '%s': %v", p.request.URL, err)
+	}
+	req.Header.Add("Accept", acceptHeader)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error on request to '%s': %v", req.URL, err)
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("'%s' returned HTTP status code: %d (%s)", req.URL, resp.StatusCode, resp.Status)
+	}
+
+	mfs, err := Parse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing response from '%s': %v", req.URL, err)
+	}
+	return mfs.Filter(p.selector), nil
+}
+
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,66 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus_Scrape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics":
+			_, _ = w.Write(testData)
+		case "/invalid":
+			_, _ = w.Write([]byte("invalid"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		path      string
+		selector  Selector
+		wantNames []string
+		wantErr   bool
+	}{
+		"valid data": {
+			path:      "/metrics",
+			wantNames: []string{"go_goroutines", "http_request_duration_seconds", "http_requests_total", "rpc_duration_seconds", "untyped_metric"},
+		},
+		"valid data with selector": {
+			path:      "/metrics",
+			selector:  testSelector(func(name string, _ Labels) bool { return name == "go_goroutines" }),
+			wantNames: []string{"go_goroutines"},
+		},
+		"invalid data": {
+			path:    "/invalid",
+			wantErr: true,
+		},
+		"404": {
+			path:    "/404",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prom := New(http.DefaultClient, web.Request{URL: srv.URL + test.path}, test.selector)
+
+			mfs, err := prom.Scrape()
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantNames, mfs.Names())
+			}
+		})
+	}
+}
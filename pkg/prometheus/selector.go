@@ -0,0 +1,7 @@
+package prometheus
+
+// Selector selects metrics by the metric family name and the metric labels.
+// See the selector package for the implementations.
+type Selector interface {
+	Matches(name string, lbs Labels) bool
+}
@@ -0,0 +1,264 @@
+// Package selector implements Prometheus metric selectors.
+//
+// Selector syntax:
+//
+//	name_pattern
+//	name_pattern{label_name op "value", ...}
+//	{label_name op "value", ...}
+//
+// name_pattern is a glob pattern (see path.Match). Supported label match operators:
+//
+//	=   equal
+//	!=  not equal
+//	=~  regular expression match (anchored)
+//	!~  regular expression not match (anchored)
+//	=*  glob match
+//	!*  glob not match
+package selector
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/netdata/go-orchestrator/pkg/prometheus"
+)
+
+type (
+	// Selector is an alias for prometheus.Selector.
+	Selector = prometheus.Selector
+
+	selector struct {
+		name     string
+		matchers []labelMatcher
+	}
+	labelMatcher struct {
+		name  string
+		op    string
+		value string
+		re    *regexp.Regexp
+	}
+	and []Selector
+	or  []Selector
+	not struct{ Selector }
+	all struct{}
+)
+
+func (s selector) Matches(name string, lbs prometheus.Labels) bool {
+	if s.name != "" {
+		if ok, _ := path.Match(s.name, name); !ok {
+			return false
+		}
+	}
+	for _, m := range s.matchers {
+		if !m.matches(lbs.Get(m.name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m labelMatcher) matches(value string) bool {
+	switch m.op {
+	case "=":
+		return value == m.value
+	case "!=":
+		return value != m.value
+	case "=~":
+		return m.re.MatchString(value)
+	case "!~":
+		return !m.re.MatchString(value)
+	case "=*":
+		ok, _ := path.Match(m.value, value)
+		return ok
+	case "!*":
+		ok, _ := path.Match(m.value, value)
+		return !ok
+	}
+	return false
+}
+
+func (s and) Matches(name string, lbs prometheus.Labels) bool {
+	for _, v := range s {
+		if !v.Matches(name, lbs) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s or) Matches(name string, lbs prometheus.Labels) bool {
+	for _, v := range s {
+		if v.Matches(name, lbs) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s not) Matches(name string, lbs prometheus.Labels) bool { return !s.Selector.Matches(name, lbs) }
+
+func (all) Matches(string, prometheus.Labels) bool { return true }
+
+// And returns a selector that matches if all the selectors match.
+func And(ss ...Selector) Selector { return and(ss) }
+
+// Or returns a selector that matches if any of the selectors matches.
+func Or(ss ...Selector) Selector { return or(ss) }
+
+// Not returns a selector that negates the given selector.
+func Not(s Selector) Selector { return not{s} }
+
+// All returns a selector that matches everything.
+func All() Selector { return all{} }
+
+// Parse parses a selector expression.
+func Parse(expr string) (Selector, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	var sel selector
+	idx := strings.IndexByte(expr, '{')
+	if idx == -1 {
+		sel.name = expr
+		return sel, checkGlob(sel.name)
+	}
+
+	sel.name = strings.TrimSpace(expr[:idx])
+	if err := checkGlob(sel.name); err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(expr, "}") {
+		return nil, fmt.Errorf("selector '%s': unterminated label set", expr)
+	}
+
+	body := expr[idx+1 : len(expr)-1]
+	for _, part := range splitMatchers(body) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		m, err := parseMatcher(part)
+		if err != nil {
+			return nil, fmt.Errorf("selector '%s': %v", expr, err)
+		}
+		sel.matchers = append(sel.matchers, m)
+	}
+	return sel, nil
+}
+
+// Expr is an allow/deny list of selector expressions.
+// Supported configuration file formats: YAML.
+type Expr struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// Empty returns true if there are no allow and deny expressions.
+func (e Expr) Empty() bool { return len(e.Allow) == 0 && len(e.Deny) == 0 }
+
+// Parse returns a selector that matches if any of the allow selectors (or all if empty)
+// matches and none of the deny selectors matches.
+func (e Expr) Parse() (Selector, error) {
+	if e.Empty() {
+		return All(), nil
+	}
+
+	var allow, deny []Selector
+	for _, v := range e.Allow {
+		s, err := Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("allow: %v", err)
+		}
+		allow = append(allow, s)
+	}
+	for _, v := range e.Deny {
+		s, err := Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("deny: %v", err)
+		}
+		deny = append(deny, s)
+	}
+
+	var sel Selector = All()
+	if len(allow) > 0 {
+		sel = Or(allow...)
+	}
+	if len(deny) > 0 {
+		sel = And(sel, Not(Or(deny...)))
+	}
+	return sel, nil
+}
+
+func parseMatcher(s string) (labelMatcher, error) {
+	s = strings.TrimSpace(s)
+
+	idx := strings.IndexAny(s, "=!")
+	if idx <= 0 {
+		return labelMatcher{}, fmt.Errorf("invalid label matcher '%s'", s)
+	}
+	if idx+2 > len(s) {
+		return labelMatcher{}, fmt.Errorf("invalid label matcher '%s'", s)
+	}
+
+	m := labelMatcher{name: strings.TrimSpace(s[:idx])}
+	rest := s[idx:]
+
+	switch {
+	case strings.HasPrefix(rest, "=~"), strings.HasPrefix(rest, "!~"),
+		strings.HasPrefix(rest, "=*"), strings.HasPrefix(rest, "!*"),
+		strings.HasPrefix(rest, "!="):
+		m.op, rest = rest[:2], rest[2:]
+	case strings.HasPrefix(rest, "="):
+		m.op, rest = rest[:1], rest[1:]
+	default:
+		return labelMatcher{}, fmt.Errorf("invalid label matcher operator in '%s'", s)
+	}
+
+	rest = strings.TrimSpace(rest)
+	if len(rest) < 2 || rest[0] != '"' || rest[len(rest)-1] != '"' {
+		return labelMatcher{}, fmt.Errorf("label matcher '%s': value must be quoted", s)
+	}
+	m.value = rest[1 : len(rest)-1]
+
+	switch m.op {
+	case "=~", "!~":
+		re, err := regexp.Compile("^(?:" + m.value + ")$")
+		if err != nil {
+			return labelMatcher{}, fmt.Errorf("label matcher '%s': %v", s, err)
+		}
+		m.re = re
+	case "=*", "!*":
+		if err := checkGlob(m.value); err != nil {
+			return labelMatcher{}, fmt.Errorf("label matcher '%s': %v", s, err)
+		}
+	}
+	return m, nil
+}
+
+// splitMatchers splits the label set body by commas that are not inside quotes.
+func splitMatchers(s string) (parts []string) {
+	var quoted bool
+	var start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func checkGlob(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
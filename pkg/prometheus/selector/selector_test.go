@@ -0,0 +1,90 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/netdata/go-orchestrator/pkg/prometheus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	lbs := prometheus.Labels{{Name: "code", Value: "200"}, {Name: "method", Value: "post"}}
+
+	tests := map[string]struct {
+		expr      string
+		name      string
+		wantMatch bool
+		wantErr   bool
+	}{
+		"name exact":              {expr: "http_requests_total", name: "http_requests_total", wantMatch: true},
+		"name exact mismatch":     {expr: "http_requests_total", name: "go_goroutines"},
+		"name glob":               {expr: "http_*", name: "http_requests_total", wantMatch: true},
+		"label equal":             {expr: `http_*{code="200"}`, name: "http_requests_total", wantMatch: true},
+		"label not equal":         {expr: `http_*{code!="200"}`, name: "http_requests_total"},
+		"label regexp":            {expr: `{code=~"2.."}`, name: "http_requests_total", wantMatch: true},
+		"label regexp anchored":   {expr: `{code=~"2"}`, name: "http_requests_total"},
+		"label not regexp":        {expr: `{code!~"5.."}`, name: "http_requests_total", wantMatch: true},
+		"label glob":              {expr: `{method=*"p*"}`, name: "http_requests_total", wantMatch: true},
+		"label not glob":          {expr: `{method!*"p*"}`, name: "http_requests_total"},
+		"multiple labels":         {expr: `{code="200", method="post"}`, name: "any", wantMatch: true},
+		"multiple labels no":      {expr: `{code="200", method="get"}`, name: "any"},
+		"missing label":           {expr: `{instance=""}`, name: "any", wantMatch: true},
+		"value with comma":        {expr: `{method!="a,b"}`, name: "any", wantMatch: true},
+		"empty":                   {expr: "", wantErr: true},
+		"bad name glob":           {expr: "http_[", wantErr: true},
+		"unterminated label set":  {expr: `name{code="200"`, wantErr: true},
+		"unquoted label value":    {expr: `name{code=200}`, wantErr: true},
+		"no label operator":       {expr: `name{code}`, wantErr: true},
+		"bad label regexp":        {expr: `name{code=~"("}`, wantErr: true},
+		"bad label glob":          {expr: `name{code=*"["}`, wantErr: true},
+		"no label name":           {expr: `name{="200"}`, wantErr: true},
+		"label op without value":  {expr: `name{code=}`, wantErr: true},
+		"label unknown operation": {expr: `name{code!"200"}`, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sel, err := Parse(test.expr)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMatch, sel.Matches(test.name, lbs))
+		})
+	}
+}
+
+func TestExpr_Parse(t *testing.T) {
+	tests := map[string]struct {
+		expr      Expr
+		name      string
+		wantMatch bool
+		wantErr   bool
+	}{
+		"empty matches everything": {expr: Expr{}, name: "any", wantMatch: true},
+		"allowed":                  {expr: Expr{Allow: []string{"a*", "b*"}}, name: "bar", wantMatch: true},
+		"not allowed":              {expr: Expr{Allow: []string{"a*", "b*"}}, name: "car"},
+		"denied":                   {expr: Expr{Deny: []string{"c*"}}, name: "car"},
+		"not denied":               {expr: Expr{Deny: []string{"c*"}}, name: "bar", wantMatch: true},
+		"allowed and denied":       {expr: Expr{Allow: []string{"*"}, Deny: []string{"c*"}}, name: "car"},
+		"invalid allow":            {expr: Expr{Allow: []string{""}}, wantErr: true},
+		"invalid deny":             {expr: Expr{Deny: []string{"["}}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sel, err := test.expr.Parse()
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMatch, sel.Matches(test.name, nil))
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/netdata/go-orchestrator/pkg/tlscfg"
+	"github.com/netdata/go-orchestrator/pkg/web"
+)
+
+const defaultTimeout = time.Second
+
+// Config is the socket client configuration. This structure is intended to be part of the module configuration.
+// Supported configuration file formats: YAML.
+type Config struct {
+	// Address is the address to connect to: 'host:port', 'tcp://host:port', 'unix:///path/to/socket'
+	// or '/path/to/socket'.
+	Address string `yaml:"address"`
+
+	// Timeout is a time limit for connecting, sending a command and reading the response.
+	// Default is 1 second.
+	Timeout web.Duration `yaml:"timeout"`
+
+	// TLSConfig specifies the TLS configuration, applies only to TCP connections.
+	tlscfg.TLSConfig `yaml:",inline"`
+}
+
+// Processor is called for every response line (without the line terminator).
+// The line is only valid until Processor returns. Returning false stops reading the response.
+type Processor func(line []byte) bool
+
+// Client sends commands to a line-based text protocol server.
+//
+// The connection is established on the first command and reused across commands.
+// It is closed on any error, the next command reconnects.
+// A typical module uses it this way:
+//   - Init: create a Client with New.
+//   - Check and Collect: send commands with Command.
+//   - Cleanup: close the connection with Disconnect.
+type Client interface {
+	// Connect connects to the server if not connected.
+	Connect() error
+	// Disconnect closes the connection if connected.
+	Disconnect() error
+	// IsConnected reports whether the connection is established.
+	IsConnected() bool
+	// Command sends the command as-is (it must include the protocol line terminator)
+	// and calls the processor for every response line until it returns false or the server closes the connection.
+	//
+	// If the connection is reused and the server has closed it (the command can't be sent, or the connection
+	// is closed or reset before any response byte is read), the command is sent once more over a new connection.
+	// It is not resent after a read timeout or a partial response, the server could have already received it.
+	Command(command string, process Processor) error
+}
+
+type client struct {
+	network   string
+	address   string
+	timeout   time.Duration
+	tlsConfig *tls.Config
+
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// New creates a new Client.
+func New(cfg Config) (Client, error) {
+	network, address, err := parseAddress(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := tlscfg.NewTLSConfig(cfg.TLSConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error on creating TLS config: %v", err)
+	}
+	if tlsConfig != nil && network == "unix" {
+		return nil, errors.New("TLS is not supported for unix sockets")
+	}
+
+	timeout := cfg.Timeout.Duration
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &client{
+		network:   network,
+		address:   address,
+		timeout:   timeout,
+		tlsConfig: tlsConfig,
+	}, nil
+}
+
+func (c *client) Connect() error {
+	if c.conn != nil {
+		return nil
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var conn net.Conn
+	var err error
+	if c.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, c.network, c.address, c.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(c.network, c.address)
+	}
+	if err != nil {
+		return fmt.Errorf("error on connecting to '%s://%s': %v", c.network, c.address, err)
+	}
+
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	return nil
+}
+
+func (c *client) Disconnect() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.reader = nil
+	return err
+}
+
+func (c *client) IsConnected() bool {
+	return c.conn != nil
+}
+
+func (c *client) Command(command string, process Processor) error {
+	reused := c.conn != nil
+
+	closed, err := c.command(command, process)
+	if err == nil {
+		return nil
+	}
+	_ = c.Disconnect()
+
+	// the server may have closed an idle connection, retry once with a new one.
+	if reused && closed {
+		if _, err = c.command(command, process); err == nil {
+			return nil
+		}
+		_ = c.Disconnect()
+	}
+	return err
+}
+
+// command sends the command and reads the response. closed reports whether the command failed
+// because the connection was closed by the server before the response (see Client.Command).
+func (c *client) command(command string, process Processor) (closed bool, err error) {
+	if err := c.Connect(); err != nil {
+		return false, err
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return false, err
+	}
+
+	if _, err := io.WriteString(c.conn, command); err != nil {
+		return true, fmt.Errorf("error on sending command to '%s': %v", c.address, err)
+	}
+
+	var n int
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			n++
+			if !process(bytes.TrimRight(line, "\r\n")) {
+				return false, nil
+			}
+		}
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, io.EOF) && n > 0 {
+			// the server closes the connection after the response.
+			_ = c.Disconnect()
+			return false, nil
+		}
+		closed = n == 0 && len(line) == 0 && (errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET))
+		return closed, fmt.Errorf("error on reading response from '%s': %v", c.address, err)
+	}
+}
+
+func parseAddress(address string) (network, addr string, err error) {
+	switch {
+	case address == "":
+		return "", "", errors.New("'address' is not set")
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp://")
+	case strings.HasPrefix(address, "/"):
+		network, addr = "unix", address
+	default:
+		network, addr = "tcp", address
+	}
+
+	if addr == "" {
+		return "", "", fmt.Errorf("invalid address '%s'", address)
+	}
+	if network == "tcp" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return "", "", fmt.Errorf("invalid address '%s': %v", address, err)
+		}
+	}
+	return network, addr, nil
+}
@@ -0,0 +1,232 @@
+package socket
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/pkg/tlscfg"
+	"github.com/netdata/go-orchestrator/pkg/web"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		wantErr bool
+	}{
+		"host:port":        {cfg: Config{Address: "127.0.0.1:6379"}},
+		"tcp scheme":       {cfg: Config{Address: "tcp://127.0.0.1:6379"}},
+		"unix scheme":      {cfg: Config{Address: "unix:///var/run/app.sock"}},
+		"unix path":        {cfg: Config{Address: "/var/run/app.sock"}},
+		"tcp with tls":     {cfg: Config{Address: "127.0.0.1:6379", TLSConfig: tlsSkipVerify()}},
+		"empty address":    {cfg: Config{}, wantErr: true},
+		"empty unix path":  {cfg: Config{Address: "unix://"}, wantErr: true},
+		"no port":          {cfg: Config{Address: "127.0.0.1"}, wantErr: true},
+		"unix with tls":    {cfg: Config{Address: "/var/run/app.sock", TLSConfig: tlsSkipVerify()}, wantErr: true},
+		"invalid tls conf": {cfg: Config{Address: "127.0.0.1:6379", TLSConfig: tlsNotExistCA()}, wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, c)
+			}
+		})
+	}
+}
+
+func TestClient_Command(t *testing.T) {
+	tests := map[string]struct {
+		network string
+		mode    serverMode
+	}{
+		"tcp, keep alive":               {network: "tcp", mode: keepAlive},
+		"tcp, server closes after resp": {network: "tcp", mode: closeAfterResponse},
+		"tcp, server closes idle conn":  {network: "tcp", mode: closeIdle},
+		"unix, keep alive":              {network: "unix", mode: keepAlive},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, cleanup := newServer(t, test.network, test.mode)
+			defer cleanup()
+
+			c, err := New(Config{Address: srv.address})
+			require.NoError(t, err)
+			defer func() { _ = c.Disconnect() }()
+
+			for i := 0; i < 3; i++ {
+				var lines []string
+				err := c.Command("stats\r\n", func(line []byte) bool {
+					if string(line) == "END" {
+						return false
+					}
+					lines = append(lines, string(line))
+					return true
+				})
+				require.NoErrorf(t, err, "command %d", i+1)
+				assert.Equalf(t, []string{"STAT cmd stats", "STAT uptime 1"}, lines, "command %d", i+1)
+			}
+		})
+	}
+}
+
+func TestClient_Command_ConnectionRefused(t *testing.T) {
+	srv, cleanup := newServer(t, "tcp", keepAlive)
+	cleanup()
+
+	c, err := New(Config{Address: srv.address})
+	require.NoError(t, err)
+
+	assert.Error(t, c.Command("stats\r\n", func([]byte) bool { return true }))
+	assert.False(t, c.IsConnected())
+}
+
+func TestClient_Command_Timeout(t *testing.T) {
+	srv, cleanup := newServer(t, "tcp", noResponse)
+	defer cleanup()
+
+	c, err := New(Config{Address: srv.address, Timeout: web.Duration{Duration: time.Millisecond * 100}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.Error(t, c.Command("stats\r\n", func([]byte) bool { return true }))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.False(t, c.IsConnected())
+}
+
+func TestClient_Command_TimeoutOnReusedConn(t *testing.T) {
+	srv, cleanup := newServer(t, "tcp", respondOnce)
+	defer cleanup()
+
+	c, err := New(Config{Address: srv.address, Timeout: web.Duration{Duration: time.Millisecond * 100}})
+	require.NoError(t, err)
+	defer func() { _ = c.Disconnect() }()
+
+	process := func(line []byte) bool { return string(line) != "END" }
+	require.NoError(t, c.Command("stats\r\n", process))
+	assert.Error(t, c.Command("stats\r\n", process))
+
+	// the server received the second command, it is not resent.
+	assert.EqualValues(t, 2, atomic.LoadInt64(&srv.commands))
+}
+
+func TestClient_ConnectDisconnect(t *testing.T) {
+	srv, cleanup := newServer(t, "tcp", keepAlive)
+	defer cleanup()
+
+	c, err := New(Config{Address: srv.address})
+	require.NoError(t, err)
+
+	assert.False(t, c.IsConnected())
+	require.NoError(t, c.Connect())
+	assert.True(t, c.IsConnected())
+	require.NoError(t, c.Connect())
+	require.NoError(t, c.Disconnect())
+	assert.False(t, c.IsConnected())
+	assert.NoError(t, c.Disconnect())
+}
+
+type serverMode int
+
+const (
+	keepAlive serverMode = iota
+	closeAfterResponse
+	closeIdle
+	noResponse
+	respondOnce
+)
+
+type server struct {
+	address  string
+	ln       net.Listener
+	mode     serverMode
+	commands int64
+}
+
+func newServer(t *testing.T, network string, mode serverMode) (*server, func()) {
+	var address, dir string
+	if network == "unix" {
+		var err error
+		dir, err = ioutil.TempDir(os.TempDir(), "netdata-go-test-socket")
+		require.NoError(t, err)
+		address = filepath.Join(dir, "server.sock")
+	} else {
+		address = "127.0.0.1:0"
+	}
+
+	ln, err := net.Listen(network, address)
+	require.NoError(t, err)
+
+	srv := &server{ln: ln, mode: mode, address: ln.Addr().String()}
+	if network == "unix" {
+		srv.address = "unix://" + srv.address
+	}
+	go srv.serve()
+
+	return srv, func() {
+		_ = ln.Close()
+		if dir != "" {
+			_ = os.RemoveAll(dir)
+		}
+	}
+}
+
+func (s *server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	rd := bufio.NewReader(conn)
+
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if n := atomic.AddInt64(&s.commands, 1); s.mode == noResponse || (s.mode == respondOnce && n > 1) {
+			continue
+		}
+
+		cmd := strings.TrimSpace(line)
+		resp := "STAT cmd " + cmd + "\r\nSTAT uptime 1\r\n"
+		if s.mode != closeAfterResponse {
+			resp += "END\r\n"
+		}
+		if _, err := conn.Write([]byte(resp)); err != nil {
+			return
+		}
+		if s.mode == closeAfterResponse || s.mode == closeIdle {
+			return
+		}
+	}
+}
+
+func tlsSkipVerify() tlscfg.TLSConfig {
+	return tlscfg.TLSConfig{InsecureSkipVerify: true}
+}
+
+func tlsNotExistCA() tlscfg.TLSConfig {
+	return tlscfg.TLSConfig{TLSCA: "testdata/not-exist.pem"}
+}
@@ -0,0 +1,58 @@
+package tlscfg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	tests := map[string]struct {
+		cfg     TLSConfig
+		wantNil bool
+		wantErr bool
+	}{
+		"not configured": {
+			cfg:     TLSConfig{},
+			wantNil: true,
+		},
+		"skip verify": {
+			cfg: TLSConfig{InsecureSkipVerify: true},
+		},
+		"ca file doesnt exist": {
+			cfg:     TLSConfig{TLSCA: "testdata/not-exist.pem"},
+			wantErr: true,
+		},
+		"cert without key": {
+			cfg:     TLSConfig{TLSCert: "testdata/not-exist.crt"},
+			wantErr: true,
+		},
+		"key without cert": {
+			cfg:     TLSConfig{TLSKey: "testdata/not-exist.key"},
+			wantErr: true,
+		},
+		"keypair doesnt exist": {
+			cfg:     TLSConfig{TLSCert: "testdata/not-exist.crt", TLSKey: "testdata/not-exist.key"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tlsCfg, err := NewTLSConfig(test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if test.wantNil {
+				assert.Nil(t, tlsCfg)
+			} else {
+				require.NotNil(t, tlsCfg)
+				assert.Equal(t, test.cfg.InsecureSkipVerify, tlsCfg.InsecureSkipVerify)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	client, err := NewHTTPClient(Client{
+		Timeout:           Duration{Duration: time.Second * 5},
+		NotFollowRedirect: true,
+		ProxyURL:          "http://127.0.0.1:3128",
+	})
+	require.NoError(t, err)
+
+	assert.IsType(t, (*http.Client)(nil), client)
+	assert.Equal(t, time.Second*5, client.Timeout)
+	assert.NotNil(t, client.CheckRedirect)
+}
+
+func TestNewHTTPClient_TLSError(t *testing.T) {
+	var cfg Client
+	cfg.TLSCA = "testdata/not-exist.pem"
+
+	_, err := NewHTTPClient(cfg)
+	assert.Error(t, err)
+}
+
+func TestHTTP_Do(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		cfg      HTTP
+		wantCode int
+	}{
+		"authorized": {
+			cfg:      HTTP{Request: Request{URL: srv.URL, Username: "user", Password: "pass"}},
+			wantCode: http.StatusOK,
+		},
+		"unauthorized": {
+			cfg:      HTTP{Request: Request{URL: srv.URL}},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewHTTPClient(test.cfg.Client)
+			require.NoError(t, err)
+			req, err := NewHTTPRequest(test.cfg.Request)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_NotFollowRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/redirected", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewHTTPClient(Client{NotFollowRedirect: true})
+	require.NoError(t, err)
+	req, err := NewHTTPRequest(Request{URL: srv.URL})
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is a time.Duration wrapper that supports YAML unmarshalling.
+// A value can be a duration string ("1s", "500ms") or a number of seconds.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	if v, err := time.ParseDuration(s); err == nil {
+		d.Duration = v
+		return nil
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		d.Duration = time.Duration(v) * time.Second
+		return nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		d.Duration = time.Duration(v * float64(time.Second))
+		return nil
+	}
+	return fmt.Errorf("unparsable duration format '%s'", s)
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDuration_UnmarshalYAML(t *testing.T) {
+	tests := map[string]struct {
+		input   string
+		wantDur time.Duration
+		wantErr bool
+	}{
+		"duration string":  {input: "timeout: 300ms", wantDur: time.Millisecond * 300},
+		"integer seconds":  {input: "timeout: 2", wantDur: time.Second * 2},
+		"float seconds":    {input: "timeout: 1.5", wantDur: time.Millisecond * 1500},
+		"unparsable value": {input: "timeout: one", wantErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg struct {
+				Timeout Duration `yaml:"timeout"`
+			}
+			err := yaml.Unmarshal([]byte(test.input), &cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.wantDur, cfg.Timeout.Duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Request is a struct that contains the fields that are needed to create *http.Request.
+// Supported configuration file formats: YAML.
+type Request struct {
+	URL             string            `yaml:"url"`
+	Body            string            `yaml:"body"`
+	Username        string            `yaml:"username"`
+	Password        string            `yaml:"password"`
+	ProxyUsername   string            `yaml:"proxy_username"`
+	ProxyPassword   string            `yaml:"proxy_password"`
+	BearerToken     string            `yaml:"bearer_token"`
+	BearerTokenFile string            `yaml:"bearer_token_file"`
+	Method          string            `yaml:"method"`
+	Headers         map[string]string `yaml:"headers"`
+}
+
+// NewHTTPRequest creates a new *http.Request based on Request fields.
+// Basic auth and bearer token are mutually exclusive, bearer token wins if both are set.
+func NewHTTPRequest(cfg Request) (*http.Request, error) {
+	var body io.Reader
+	if cfg.Body != "" {
+		body = strings.NewReader(cfg.Body)
+	}
+
+	req, err := http.NewRequest(cfg.Method, cfg.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := bearerToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case cfg.Username != "" || cfg.Password != "":
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	if cfg.ProxyUsername != "" && cfg.ProxyPassword != "" {
+		basicAuth := base64.StdEncoding.EncodeToString([]byte(cfg.ProxyUsername + ":" + cfg.ProxyPassword))
+		req.Header.Set("Proxy-Authorization", "Basic "+basicAuth)
+	}
+
+	for k, v := range cfg.Headers {
+		switch k {
+		case "host", "Host":
+			req.Host = v
+		default:
+			req.Header.Set(k, v)
+		}
+	}
+
+	return req, nil
+}
+
+func bearerToken(cfg Request) (string, error) {
+	if cfg.BearerToken != "" {
+		return cfg.BearerToken, nil
+	}
+	if cfg.BearerTokenFile == "" {
+		return "", nil
+	}
+	bs, err := ioutil.ReadFile(cfg.BearerTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read bearer token file '%s': %v", cfg.BearerTokenFile, err)
+	}
+	return strings.TrimSpace(string(bs)), nil
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPRequest(t *testing.T) {
+	tests := map[string]struct {
+		req     Request
+		check   func(t *testing.T, r *http.Request)
+		wantErr bool
+	}{
+		"basic auth": {
+			req: Request{URL: "http://127.0.0.1", Username: "user", Password: "pass"},
+			check: func(t *testing.T, r *http.Request) {
+				user, pass, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "user", user)
+				assert.Equal(t, "pass", pass)
+			},
+		},
+		"bearer token wins over basic auth": {
+			req: Request{URL: "http://127.0.0.1", Username: "user", Password: "pass", BearerToken: "token"},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			},
+		},
+		"proxy auth": {
+			req: Request{URL: "http://127.0.0.1", ProxyUsername: "user", ProxyPassword: "pass"},
+			check: func(t *testing.T, r *http.Request) {
+				auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+				assert.Equal(t, "Basic "+auth, r.Header.Get("Proxy-Authorization"))
+			},
+		},
+		"headers and host": {
+			req: Request{URL: "http://127.0.0.1", Headers: map[string]string{"X-Key": "value", "host": "example.com"}},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "value", r.Header.Get("X-Key"))
+				assert.Equal(t, "example.com", r.Host)
+			},
+		},
+		"method and body": {
+			req: Request{URL: "http://127.0.0.1", Method: http.MethodPost, Body: "body"},
+			check: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				bs, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "body", string(bs))
+			},
+		},
+		"invalid URL": {
+			req:     Request{URL: "http://[::1]:namedport"},
+			wantErr: true,
+		},
+		"bearer token file doesnt exist": {
+			req:     Request{URL: "http://127.0.0.1", BearerTokenFile: "testdata/not-exist"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := NewHTTPRequest(test.req)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			test.check(t, req)
+		})
+	}
+}
+
+func TestNewHTTPRequest_BearerTokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-web-request")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	file := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(file, []byte("token\n"), 0644))
+
+	req, err := NewHTTPRequest(Request{URL: "http://127.0.0.1", BearerTokenFile: file})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(req.Header.Get("Authorization"), " token"))
+}
@@ -0,0 +1,37 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestHTTP_Unmarshal(t *testing.T) {
+	// it mimics how job configs are applied to modules (marshal the config map, unmarshal into the module).
+	jobCfg := map[string]interface{}{
+		"name":            "job",
+		"url":             "https://127.0.0.1:8080/status",
+		"username":        "user",
+		"password":        "pass",
+		"headers":         map[string]string{"X-Key": "value"},
+		"timeout":         2,
+		"tls_skip_verify": true,
+	}
+	var mod struct {
+		HTTP `yaml:",inline"`
+	}
+
+	bs, err := yaml.Marshal(jobCfg)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(bs, &mod))
+
+	assert.Equal(t, "https://127.0.0.1:8080/status", mod.URL)
+	assert.Equal(t, "user", mod.Username)
+	assert.Equal(t, "pass", mod.Password)
+	assert.Equal(t, map[string]string{"X-Key": "value"}, mod.Headers)
+	assert.Equal(t, time.Second*2, mod.Timeout.Duration)
+	assert.True(t, mod.InsecureSkipVerify)
+}
@@ -0,0 +1,275 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/build"
+	"github.com/netdata/go-orchestrator/job/run"
+	"github.com/netdata/go-orchestrator/module"
+)
+
+// Admin API (HTTP over the Unix socket, JSON responses):
+//   - GET  /modules                    registered modules and whether they are enabled.
+//   - GET  /configs                    discovered configs per source.
+//   - GET  /jobs                       jobs with their build state and data collection stats.
+//   - POST /jobs/stop?job=<full name>  stop the running job.
+//   - POST /jobs/start?job=<full name> start the stopped (or failed) job.
+//   - POST /discovery/rediscover       reload the configuration and rediscover configs.
+type adminAPI struct {
+	plugin  *Plugin
+	builder *build.Manager
+	runner  *run.Manager
+}
+
+type (
+	adminModule struct {
+		Name               string `json:"name"`
+		Enabled            bool   `json:"enabled"`
+		DisabledByDefault  bool   `json:"disabled_by_default"`
+		UpdateEvery        int    `json:"update_every"`
+		AutoDetectionRetry int    `json:"autodetection_retry"`
+		Priority           int    `json:"priority"`
+	}
+	adminJob struct {
+		FullName      string     `json:"full_name"`
+		Module        string     `json:"module"`
+		Name          string     `json:"name"`
+		Source        string     `json:"source"`
+		Provider      string     `json:"provider"`
+		State         string     `json:"state"`
+		Running       bool       `json:"running"`
+		Retries       int        `json:"retries"`
+		Penalty       int        `json:"penalty"`
+		LastError     string     `json:"last_error,omitempty"`
+		LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+	}
+	adminCommandResult struct {
+		Job   string `json:"job,omitempty"`
+		State string `json:"state,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+)
+
+type jobStatsProvider interface {
+	Stats() module.JobStats
+}
+
+func (p *Plugin) serveAdmin(ctx context.Context, builder *build.Manager, runner *run.Manager) {
+	path := p.AdminSocket
+
+	ln, err := listenAdmin(path)
+	if err != nil {
+		p.Errorf("admin API: %v", err)
+		return
+	}
+
+	api := &adminAPI{plugin: p, builder: builder, runner: runner}
+	srv := &http.Server{Handler: api.handler()}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			p.Errorf("admin API: %v", err)
+		}
+	}()
+	p.Infof("admin API is listening on '%s'", path)
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+	<-done
+}
+
+// listenAdmin listens on the Unix socket, the socket is accessible only by the plugin user.
+// The existing socket file is removed only if nothing is listening on it
+// (it is left if the previous instance was killed), another instance socket is not taken over.
+func listenAdmin(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		switch {
+		case err == nil:
+			_ = conn.Close()
+			return nil, fmt.Errorf("'%s' is in use by another process", path)
+		case errors.Is(err, syscall.ECONNREFUSED):
+			_ = os.Remove(path)
+		default:
+			return nil, fmt.Errorf("check '%s': %v", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// the admin API can stop and start jobs, the socket permissions should not depend on the umask.
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+func (a *adminAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/modules", a.onlyMethod(http.MethodGet, a.modules))
+	mux.HandleFunc("/configs", a.onlyMethod(http.MethodGet, a.configs))
+	mux.HandleFunc("/jobs", a.onlyMethod(http.MethodGet, a.jobs))
+	mux.HandleFunc("/jobs/stop", a.onlyMethod(http.MethodPost, a.stopJob))
+	mux.HandleFunc("/jobs/start", a.onlyMethod(http.MethodPost, a.startJob))
+	mux.HandleFunc("/discovery/rediscover", a.onlyMethod(http.MethodPost, a.rediscover))
+	return mux
+}
+
+func (a *adminAPI) onlyMethod(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, adminCommandResult{Error: "method not allowed"})
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (a *adminAPI) modules(w http.ResponseWriter, _ *http.Request) {
+	enabled := make(map[string]bool)
+	for _, name := range a.builder.EnabledModules() {
+		enabled[name] = true
+	}
+
+	mods := make([]adminModule, 0, len(a.plugin.ModuleRegistry))
+	for name, creator := range a.plugin.ModuleRegistry {
+		mods = append(mods, adminModule{
+			Name:               name,
+			Enabled:            enabled[name],
+			DisabledByDefault:  creator.Disabled,
+			UpdateEvery:        creator.UpdateEvery,
+			AutoDetectionRetry: creator.AutoDetectionRetry,
+			Priority:           creator.Priority,
+		})
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name < mods[j].Name })
+	writeJSON(w, http.StatusOK, mods)
+}
+
+func (a *adminAPI) configs(w http.ResponseWriter, _ *http.Request) {
+	groups := make(map[string][]interface{})
+	for source, cfgs := range a.builder.Groups() {
+		for _, cfg := range cfgs {
+			groups[source] = append(groups[source], jsonCompatible(map[string]interface{}(cfg)))
+		}
+	}
+	writeJSON(w, http.StatusOK, groups)
+}
+
+func (a *adminAPI) jobs(w http.ResponseWriter, _ *http.Request) {
+	statuses := a.builder.Statuses()
+	jobs := make([]adminJob, 0, len(statuses))
+
+	for _, st := range statuses {
+		job := adminJob{
+			FullName: st.Config.FullName(),
+			Module:   st.Config.Module(),
+			Name:     st.Config.Name(),
+			Source:   st.Config.Source(),
+			Provider: st.Config.Provider(),
+			State:    st.State,
+		}
+		if st.State == "success" {
+			if j, ok := a.runner.Lookup(job.FullName); ok {
+				job.Running = true
+				if sp, ok := j.(jobStatsProvider); ok {
+					stats := sp.Stats()
+					job.Retries = stats.Retries
+					job.Penalty = stats.Penalty
+					job.LastError = stats.LastError
+					if !stats.LastErrorTime.IsZero() {
+						job.LastErrorTime = &stats.LastErrorTime
+					}
+				}
+			}
+		}
+		jobs = append(jobs, job)
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (a *adminAPI) stopJob(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("job")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, adminCommandResult{Error: "'job' parameter is not set"})
+		return
+	}
+	if err := a.builder.StopJob(r.Context(), name); err != nil {
+		writeJSON(w, http.StatusConflict, adminCommandResult{Job: name, Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, adminCommandResult{Job: name, State: "stopped"})
+}
+
+func (a *adminAPI) startJob(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("job")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, adminCommandResult{Error: "'job' parameter is not set"})
+		return
+	}
+	state, err := a.builder.StartJob(r.Context(), name)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, adminCommandResult{Job: name, State: state, Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, adminCommandResult{Job: name, State: state})
+}
+
+func (a *adminAPI) rediscover(w http.ResponseWriter, _ *http.Request) {
+	a.plugin.triggerReload()
+	writeJSON(w, http.StatusAccepted, adminCommandResult{})
+}
+
+// jsonCompatible converts YAML decoded maps (map[interface{}]interface{}) to JSON encodable ones.
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = jsonCompatible(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	bs, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("json marshal: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(bs)
+}
@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugin_serveAdmin(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-admin")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	p := New(Config{Name: "test", AdminSocket: filepath.Join(dir, "admin.sock")})
+	var buf bytes.Buffer
+	p.Out = &buf
+
+	var mux sync.Mutex
+	stats := make(map[string]int)
+	p.ModuleRegistry = prepareRegistry(&mux, stats, "module1", "module2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); p.run(ctx) }()
+
+	time.Sleep(time.Second * 2)
+
+	client := &http.Client{
+		Timeout: time.Second * 5,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", p.AdminSocket)
+			},
+		},
+	}
+	do := func(method, path string, v interface{}) int {
+		req, err := http.NewRequest(method, "http://admin"+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		if v != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+
+	var mods []adminModule
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/modules", &mods))
+	require.Len(t, mods, 2)
+	assert.Equal(t, "module1", mods[0].Name)
+	assert.True(t, mods[0].Enabled)
+
+	var cfgs map[string][]map[string]interface{}
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/configs", &cfgs))
+	assert.Len(t, cfgs, 2)
+	assert.Len(t, cfgs["module1"], 1)
+
+	var jobs []adminJob
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/jobs", &jobs))
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "module1", jobs[0].FullName)
+	assert.Equal(t, "success", jobs[0].State)
+	assert.True(t, jobs[0].Running)
+	assert.Equal(t, "dummy", jobs[0].Provider)
+
+	var res adminCommandResult
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/jobs/stop?job=module1", &res))
+	assert.Equal(t, "stopped", res.State)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/jobs/stop?job=module1", nil))
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/jobs/stop?job=not_exist", nil))
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/jobs/stop", nil))
+
+	jobs = nil
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/jobs", &jobs))
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "stopped", jobs[0].State)
+	assert.False(t, jobs[0].Running)
+
+	res = adminCommandResult{}
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/jobs/start?job=module1", &res))
+	assert.Equal(t, "success", res.State)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/jobs/start?job=module1", nil))
+
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/discovery/rediscover", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodPost, "/jobs", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "/jobs/stop?job=module1", nil))
+
+	cancel()
+	wg.Wait()
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 2, stats["module1_init"])
+	assert.Equal(t, 2, stats["module1_cleanup"])
+	_, err = os.Stat(p.AdminSocket)
+	assert.True(t, os.IsNotExist(err), "socket file is removed")
+}
+
+func TestListenAdmin(t *testing.T) {
+	tests := map[string]struct {
+		prepare func(t *testing.T, path string) func()
+		wantErr bool
+	}{
+		"no socket file": {
+			prepare: func(*testing.T, string) func() { return func() {} },
+		},
+		"stale socket file": {
+			prepare: func(t *testing.T, path string) func() {
+				ln, err := net.Listen("unix", path)
+				require.NoError(t, err)
+				ln.(*net.UnixListener).SetUnlinkOnClose(false)
+				require.NoError(t, ln.Close())
+				return func() {}
+			},
+		},
+		"socket is in use": {
+			prepare: func(t *testing.T, path string) func() {
+				ln, err := net.Listen("unix", path)
+				require.NoError(t, err)
+				return func() { _ = ln.Close() }
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-admin-listen")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			path := filepath.Join(dir, "admin.sock")
+			cleanup := test.prepare(t, path)
+			defer cleanup()
+
+			ln, err := listenAdmin(path)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				_, err = os.Stat(path)
+				assert.NoError(t, err, "another instance socket file is not removed")
+				return
+			}
+			require.NoError(t, err)
+			defer func() { _ = ln.Close() }()
+
+			fi, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+		})
+	}
+}
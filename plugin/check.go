@@ -0,0 +1,178 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/file"
+	"github.com/netdata/go-orchestrator/module"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CheckConfig loads the plugin configuration file and the modules configuration files the way the plugin does,
+// and writes the effective (merged with defaults) job configs and found problems to w.
+// Every job config is unmarshalled into its module, but no job is started.
+// It returns an error if any problem is found.
+func (p *Plugin) CheckConfig(w io.Writer) error {
+	c := &configChecker{w: w, seen: make(map[string]string)}
+	c.check(p)
+	c.printf("\nfound %d error(s), %d warning(s)\n", c.errors, c.warnings)
+
+	if c.errors > 0 {
+		return fmt.Errorf("configuration check: found %d error(s)", c.errors)
+	}
+	return nil
+}
+
+type configChecker struct {
+	w        io.Writer
+	errors   int
+	warnings int
+	seen     map[string]string // job full name => source
+}
+
+func (c *configChecker) check(p *Plugin) {
+	cfg, err := p.readPluginConfig()
+	if err != nil {
+		c.errorf("plugin config: %v", err)
+		return
+	}
+	c.printf("plugin config: %s\n", cfg)
+	if !cfg.Enabled {
+		c.warnf("plugin is disabled in the configuration file")
+		return
+	}
+
+	enabled := p.loadEnabledModules(cfg)
+	if len(enabled) == 0 {
+		c.warnf("no modules to run")
+		return
+	}
+	c.printf("enabled modules: %s\n", strings.Join(sortedNames(enabled), ", "))
+
+	discCfg := p.buildDiscoveryConf(cfg, enabled)
+	for _, name := range discCfg.Dummy.Names {
+		c.printf("module '%s': config file not found, a job with the default config will be started\n", name)
+	}
+
+	var paths []string
+	paths = append(paths, discCfg.File.Read...)
+	for _, pattern := range discCfg.File.Watch {
+		matches, err := file.Glob(pattern)
+		if err != nil {
+			c.errorf("watch path '%s': %v", pattern, err)
+			continue
+		}
+		for _, path := range matches {
+			if fi, err := os.Stat(path); err == nil && fi.Mode().IsRegular() && !file.IsExcluded(discCfg.File.Exclude, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	// all registered modules: a job of a registered but not enabled module is not an error.
+	reg := p.buildConfGroupRegistry(cfg, p.ModuleRegistry)
+	for _, path := range paths {
+		c.checkFile(p, reg, enabled, path)
+	}
+}
+
+func (c *configChecker) checkFile(p *Plugin, reg confgroup.Registry, enabled module.Registry, path string) {
+	c.printf("\nfile '%s':\n", path)
+
+	group, unknown, err := file.Check(reg, path)
+	if err != nil {
+		c.errorf("file '%s': %v", path, err)
+		return
+	}
+	for _, name := range unknown {
+		if name == "" {
+			c.errorf("file '%s': a job without the module", path)
+		} else {
+			c.errorf("file '%s': unknown module '%s'", path, name)
+		}
+	}
+	if group == nil || len(group.Configs) == 0 {
+		c.printf("  no jobs\n")
+		return
+	}
+
+	for _, cfg := range group.Configs {
+		fullName := cfg.FullName()
+		c.printf("  job '%s' (module '%s'):\n", fullName, cfg.Module())
+
+		bs, err := yaml.Marshal(cfg)
+		if err == nil {
+			c.printf("%s", indent(string(bs), "    "))
+		}
+
+		if prev, ok := c.seen[fullName]; ok {
+			// the same as at runtime: the job is skipped if a job with the same name is already started.
+			c.warnf("file '%s': job '%s' is duplicated, it is already defined in '%s', only one of them will be started",
+				path, fullName, prev)
+		} else {
+			c.seen[fullName] = path
+		}
+
+		if _, ok := enabled[cfg.Module()]; !ok {
+			c.warnf("file '%s': job '%s': module '%s' is not enabled, the job will not be started",
+				path, fullName, cfg.Module())
+		}
+
+		if err := unmarshalToModule(p, cfg); err != nil {
+			c.errorf("file '%s': job '%s': %v", path, fullName, err)
+		}
+	}
+}
+
+func (c *configChecker) printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(c.w, format, a...)
+}
+
+func (c *configChecker) errorf(format string, a ...interface{}) {
+	c.errors++
+	c.printf("ERROR: "+format+"\n", a...)
+}
+
+func (c *configChecker) warnf(format string, a ...interface{}) {
+	c.warnings++
+	c.printf("WARNING: "+format+"\n", a...)
+}
+
+// unmarshalToModule unmarshals the job config into a new module instance the way the builder does.
+func unmarshalToModule(p *Plugin, cfg confgroup.Config) error {
+	creator, ok := p.ModuleRegistry[cfg.Module()]
+	if !ok || creator.Create == nil {
+		return errors.New("module can't be created")
+	}
+	bs, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(bs, creator.Create())
+}
+
+func sortedNames(m module.Registry) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type checkModule struct {
+	module.MockModule `yaml:"-"`
+	Port              int `yaml:"port"`
+}
+
+func TestPlugin_CheckConfig(t *testing.T) {
+	tests := map[string]struct {
+		files       map[string]string
+		wantErr     bool
+		wantOutputs []string
+	}{
+		"valid config": {
+			files: map[string]string{
+				"test.conf":    "modules:\n  module1: yes\n",
+				"module1.conf": "jobs:\n  - name: job1\n    port: 80\n",
+			},
+			wantOutputs: []string{
+				"enabled modules: module1, module2",
+				"job 'module1_job1' (module 'module1')",
+				"port: 80",
+				"update_every: 1",
+				"module 'module2': config file not found",
+				"found 0 error(s), 0 warning(s)",
+			},
+		},
+		"invalid plugin config": {
+			files: map[string]string{
+				"test.conf": "modules: [\n",
+			},
+			wantErr:     true,
+			wantOutputs: []string{"ERROR: plugin config:"},
+		},
+		"type error": {
+			files: map[string]string{
+				"module1.conf": "jobs:\n  - name: job1\n    port: http\n",
+			},
+			wantErr:     true,
+			wantOutputs: []string{"ERROR: file", "job 'module1_job1': yaml: unmarshal errors"},
+		},
+		"duplicate and unknown module": {
+			files: map[string]string{
+				"module1.conf": "jobs:\n  - name: job1\n",
+				"sd/jobs.conf": "- module: module1\n  name: job1\n- module: unknown\n  name: job1\n",
+			},
+			wantErr: true,
+			wantOutputs: []string{
+				"WARNING: file",
+				"job 'module1_job1' is duplicated",
+				"ERROR: file",
+				"unknown module 'unknown'",
+				"found 1 error(s), 1 warning(s)",
+			},
+		},
+		"disabled module": {
+			files: map[string]string{
+				"test.conf":    "default_run: no\nmodules:\n  module1: yes\n",
+				"sd/jobs.conf": "- module: module2\n  name: job1\n",
+			},
+			wantOutputs: []string{"WARNING: file", "module 'module2' is not enabled", "found 0 error(s), 1 warning(s)"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-check")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "sd"), 0755))
+			for name, content := range test.files {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			p := New(Config{
+				Name:              "test",
+				ConfDir:           []string{dir},
+				ModulesConfDir:    []string{dir},
+				ModulesSDConfPath: []string{filepath.Join(dir, "sd", "*.conf")},
+			})
+			p.ModuleRegistry = module.Registry{}
+			for _, name := range []string{"module1", "module2"} {
+				p.ModuleRegistry.Register(name, module.Creator{Create: func() module.Module { return &checkModule{} }})
+			}
+
+			var buf bytes.Buffer
+			err = p.CheckConfig(&buf)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, want := range test.wantOutputs {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
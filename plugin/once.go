@@ -0,0 +1,252 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/build"
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery/file"
+	"github.com/netdata/go-orchestrator/module"
+)
+
+// OnceConfig is the one-shot collection mode configuration.
+type OnceConfig struct {
+	// Module is the module name, it is required.
+	Module string
+	// Job is the job name, the first job found in the module configuration files is used if it is not set.
+	Job string
+	// Count is the number of data collections, default is 1.
+	Count int
+	// Format is the output format: "table" (default) or "json".
+	Format string
+}
+
+type (
+	onceResult struct {
+		Job           string           `json:"job"`
+		Source        string           `json:"source"`
+		AutoDetection onceDuration     `json:"autodetection"`
+		Collections   []onceCollection `json:"collections"`
+	}
+	onceCollection struct {
+		Duration onceDuration     `json:"duration"`
+		Error    string           `json:"error,omitempty"`
+		Metrics  map[string]int64 `json:"metrics"`
+		Charts   []onceChart      `json:"charts"`
+	}
+	onceChart struct {
+		ID    string    `json:"id"`
+		Title string    `json:"title"`
+		Units string    `json:"units"`
+		Dims  []onceDim `json:"dimensions"`
+	}
+	onceDim struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Value *int64 `json:"value"`
+	}
+	onceDuration time.Duration
+)
+
+func (d onceDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// RunOnce runs AutoDetection and cfg.Count data collections (every update_every seconds) of a single job
+// and writes the collected values per chart and the timings to w. The plugin output is not written.
+// It is intended for modules development and debugging.
+func (p *Plugin) RunOnce(w io.Writer, cfg OnceConfig) error {
+	if cfg.Module == "" || cfg.Module == "all" {
+		return errors.New("one-shot mode: module is not set")
+	}
+	creator, ok := p.ModuleRegistry[cfg.Module]
+	if !ok {
+		return fmt.Errorf("one-shot mode: module '%s' is not registered", cfg.Module)
+	}
+	if cfg.Count <= 0 {
+		cfg.Count = 1
+	}
+
+	jobCfg, err := p.findJobConfig(cfg.Module, cfg.Job)
+	if err != nil {
+		return fmt.Errorf("one-shot mode: %v", err)
+	}
+
+	builder := build.NewManager()
+	builder.PluginName = p.Name
+	builder.Loggers = p.loggers
+	builder.Out = ioutil.Discard
+	builder.Modules = module.Registry{cfg.Module: creator}
+
+	job, err := builder.BuildJob(jobCfg)
+	if err != nil {
+		return fmt.Errorf("one-shot mode: job '%s': %v", jobCfg.FullName(), err)
+	}
+
+	res := onceResult{Job: jobCfg.FullName(), Source: jobCfg.Source()}
+
+	now := time.Now()
+	ok = job.AutoDetection()
+	res.AutoDetection = onceDuration(time.Since(now))
+	if !ok {
+		return fmt.Errorf("one-shot mode: job '%s': autodetection failed", jobCfg.FullName())
+	}
+	defer job.Cleanup()
+
+	interval := time.Duration(jobCfg.UpdateEvery()) * time.Second
+	for i := 0; i < cfg.Count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		now = time.Now()
+		mx, err := job.CollectOnce()
+		coll := onceCollection{Duration: onceDuration(time.Since(now)), Metrics: mx}
+		if err != nil {
+			coll.Error = err.Error()
+		}
+		coll.Charts = onceCharts(job.Charts(), mx)
+		res.Collections = append(res.Collections, coll)
+	}
+
+	if cfg.Format == "json" {
+		bs, err := json.MarshalIndent(res, "", " ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", bs)
+		return err
+	}
+	return writeOnceTable(w, res)
+}
+
+// findJobConfig looks for the job config in the module configuration file and the SD configuration files.
+// The module default config is used if there is no module configuration file.
+func (p *Plugin) findJobConfig(moduleName, jobName string) (confgroup.Config, error) {
+	cfg := p.loadPluginConfig()
+	reg := p.buildConfGroupRegistry(cfg, module.Registry{moduleName: p.ModuleRegistry[moduleName]})
+
+	var paths []string
+	confPath, err := p.ModulesConfDir.Find(moduleName + ".conf")
+	if err == nil {
+		paths = append(paths, confPath)
+	}
+	for _, pattern := range p.ModulesSDConfPath {
+		matches, _ := file.Glob(pattern)
+		for _, path := range matches {
+			if isRegularFile(path) && !file.IsExcluded(cfg.FileWatcher.Exclude, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	for _, path := range paths {
+		group, _, err := file.Check(reg, path)
+		if err != nil {
+			return nil, fmt.Errorf("file '%s': %v", path, err)
+		}
+		if group == nil {
+			continue
+		}
+		for _, cfg := range group.Configs {
+			if cfg.Module() != moduleName {
+				continue
+			}
+			if jobName == "" || cfg.Name() == jobName || cfg.FullName() == jobName {
+				cfg.SetSource(path)
+				return cfg, nil
+			}
+		}
+	}
+
+	if confPath == "" && (jobName == "" || jobName == moduleName) {
+		def, _ := reg.Lookup(moduleName)
+		cfg := confgroup.Config{}
+		cfg.SetModule(moduleName)
+		cfg.SetSource(moduleName)
+		cfg.SetProvider("dummy")
+		cfg.Apply(def)
+		return cfg, nil
+	}
+	if jobName == "" {
+		return nil, fmt.Errorf("no jobs found for module '%s'", moduleName)
+	}
+	return nil, fmt.Errorf("job '%s' not found for module '%s'", jobName, moduleName)
+}
+
+func onceCharts(charts *module.Charts, mx map[string]int64) []onceChart {
+	if charts == nil {
+		return nil
+	}
+	var res []onceChart
+	for _, chart := range *charts {
+		if chart.Obsolete {
+			continue
+		}
+		c := onceChart{ID: chart.ID, Title: chart.Title, Units: chart.Units}
+		for _, dim := range chart.Dims {
+			d := onceDim{ID: dim.ID, Name: dim.Name}
+			if d.Name == "" {
+				d.Name = dim.ID
+			}
+			if v, ok := mx[dim.ID]; ok {
+				d.Value = &v
+			}
+			c.Dims = append(c.Dims, d)
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+func writeOnceTable(w io.Writer, res onceResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(tw, "job '%s' (source '%s'), autodetection took %s\n", res.Job, res.Source,
+		time.Duration(res.AutoDetection))
+	for i, coll := range res.Collections {
+		_, _ = fmt.Fprintf(tw, "\ncollection %d/%d took %s\n", i+1, len(res.Collections), time.Duration(coll.Duration))
+		if coll.Error != "" {
+			_, _ = fmt.Fprintf(tw, "ERROR: %s\n", coll.Error)
+			continue
+		}
+		if len(coll.Metrics) == 0 {
+			_, _ = fmt.Fprintln(tw, "no metrics collected")
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, chart := range coll.Charts {
+			_, _ = fmt.Fprintf(tw, "%s (%s, %s)\n", chart.ID, chart.Title, chart.Units)
+			for _, dim := range chart.Dims {
+				seen[dim.ID] = true
+				value := "-"
+				if dim.Value != nil {
+					value = fmt.Sprint(*dim.Value)
+				}
+				_, _ = fmt.Fprintf(tw, "  %s\t%s\t\n", dim.Name, value)
+			}
+		}
+
+		var unused []string
+		for id := range coll.Metrics {
+			if !seen[id] {
+				unused = append(unused, id)
+			}
+		}
+		if len(unused) > 0 {
+			sort.Strings(unused)
+			_, _ = fmt.Fprintln(tw, "collected, but not used by charts")
+			for _, id := range unused {
+				_, _ = fmt.Fprintf(tw, "  %s\t%d\t\n", id, coll.Metrics[id])
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/netdata/go-orchestrator/module"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugin_RunOnce(t *testing.T) {
+	tests := map[string]struct {
+		files       map[string]string
+		cfg         OnceConfig
+		wantErr     bool
+		wantOutputs []string
+	}{
+		"default config": {
+			cfg: OnceConfig{Module: "module1"},
+			wantOutputs: []string{
+				"job 'module1' (source 'module1')",
+				"collection 1/1 took",
+				"id (title, units)",
+				"dim1  1",
+				"dim2  -",
+				"collected, but not used by charts",
+				"id3  3",
+			},
+		},
+		"job from config file": {
+			files: map[string]string{"module1.conf": "jobs:\n  - name: job1\n  - name: job2\n"},
+			cfg:   OnceConfig{Module: "module1", Job: "job2", Count: 2},
+			wantOutputs: []string{
+				"job 'module1_job2'",
+				"collection 2/2 took",
+			},
+		},
+		"job not found": {
+			files:   map[string]string{"module1.conf": "jobs:\n  - name: job1\n"},
+			cfg:     OnceConfig{Module: "module1", Job: "job2"},
+			wantErr: true,
+		},
+		"module not set": {
+			cfg:     OnceConfig{Module: "all"},
+			wantErr: true,
+		},
+		"module not registered": {
+			cfg:     OnceConfig{Module: "module2"},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := newOnceTestPlugin(t, test.files)
+			defer func() { _ = os.RemoveAll(p.ModulesConfDir[0]) }()
+
+			var buf bytes.Buffer
+			err := p.RunOnce(&buf, test.cfg)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, want := range test.wantOutputs {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestPlugin_RunOnce_JSON(t *testing.T) {
+	p := newOnceTestPlugin(t, nil)
+	defer func() { _ = os.RemoveAll(p.ModulesConfDir[0]) }()
+
+	var buf bytes.Buffer
+	require.NoError(t, p.RunOnce(&buf, OnceConfig{Module: "module1", Format: "json"}))
+
+	var res struct {
+		Job         string
+		Collections []struct {
+			Metrics map[string]int64
+			Charts  []struct {
+				ID         string
+				Dimensions []struct {
+					ID    string
+					Value *int64
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+
+	assert.Equal(t, "module1", res.Job)
+	require.Len(t, res.Collections, 1)
+	assert.Equal(t, map[string]int64{"dim1": 1, "id3": 3}, res.Collections[0].Metrics)
+	require.Len(t, res.Collections[0].Charts, 1)
+	require.Len(t, res.Collections[0].Charts[0].Dimensions, 2)
+	assert.Equal(t, int64(1), *res.Collections[0].Charts[0].Dimensions[0].Value)
+	assert.Nil(t, res.Collections[0].Charts[0].Dimensions[1].Value)
+}
+
+func newOnceTestPlugin(t *testing.T, files map[string]string) *Plugin {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-once")
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	p := New(Config{Name: "test", ModulesConfDir: []string{dir}})
+	p.ModuleRegistry = module.Registry{}
+	p.ModuleRegistry.Register("module1", module.Creator{
+		Create: func() module.Module {
+			return &module.MockModule{
+				ChartsFunc: func() *module.Charts {
+					return &module.Charts{
+						&module.Chart{ID: "id", Title: "title", Units: "units", Dims: module.Dims{
+							{ID: "dim1"},
+							{ID: "dim2"},
+						}},
+					}
+				},
+				CollectFunc: func() map[string]int64 {
+					return map[string]int64{"dim1": 1, "id3": 3}
+				},
+			}
+		},
+	})
+	return p
+}
@@ -25,6 +25,9 @@ import (
 
 var isTerminal = isatty.IsTerminal(os.Stdout.Fd())
 
+// shutdownTimeout is the time limit for the graceful shutdown, the plugin exits forcibly after it.
+const shutdownTimeout = time.Second * 10
+
 // Config is Plugin configuration.
 type Config struct {
 	Name              string
@@ -33,9 +36,22 @@ type Config struct {
 	ModulesSDConfPath []string
 	StateFile         string
 	LockDir           string
-	ModuleRegistry    module.Registry
-	RunModule         string
-	MinUpdateEvery    int
+	// ModuleRegistry is the registry of available modules, default is module.DefaultRegistry.
+	ModuleRegistry module.Registry
+	// Loggers creates the plugin loggers, default is the logger package default factory.
+	// Plugins running in the same process should use separate factories.
+	Loggers *logger.Factory
+	// RunModule is the comma separated list of module names and glob patterns to run,
+	// '!' prefix excludes modules ("nginx,redis", "all,!mysql", "!mysql,!redis"). Default is "all".
+	// Modules selected by the exact name are run even if disabled by default or in the configuration file.
+	RunModule      string
+	MinUpdateEvery int
+	// LogLevel is the logging severity level name ("debug", "info", ...), default is "info".
+	LogLevel string
+	// AdminSocket is the admin API Unix socket path, the admin API is disabled if it is not set.
+	AdminSocket string
+	// DiscoveryProviders are additional service discovery providers, they run along with the built-in ones.
+	DiscoveryProviders discovery.Providers
 }
 
 // Plugin represents orchestrator.
@@ -48,60 +64,102 @@ type Plugin struct {
 	LockDir           string
 	RunModule         string
 	MinUpdateEvery    int
+	AdminSocket       string
 	ModuleRegistry    module.Registry
-	Out               io.Writer
-	api               *netdataapi.API
+	// DiscoveryProviders are additional service discovery providers.
+	DiscoveryProviders discovery.Providers
+	Out                io.Writer
+	api                *netdataapi.API
+	reloadCh           chan struct{}
+	loggers            *logger.Factory
+	// outLock serializes the plugin jobs writes to Out.
+	outLock sync.Locker
 	*logger.Logger
 }
 
 // New creates a new Plugin.
 func New(cfg Config) *Plugin {
 	p := &Plugin{
-		Name:              cfg.Name,
-		ConfDir:           cfg.ConfDir,
-		ModulesConfDir:    cfg.ModulesConfDir,
-		ModulesSDConfPath: cfg.ModulesSDConfPath,
-		StateFile:         cfg.StateFile,
-		LockDir:           cfg.LockDir,
-		RunModule:         cfg.RunModule,
-		MinUpdateEvery:    cfg.MinUpdateEvery,
-		ModuleRegistry:    module.DefaultRegistry,
-		Out:               os.Stdout,
-	}
-
-	logger.Prefix = p.Name
-	p.Logger = logger.New("main", "main")
+		Name:               cfg.Name,
+		ConfDir:            cfg.ConfDir,
+		ModulesConfDir:     cfg.ModulesConfDir,
+		ModulesSDConfPath:  cfg.ModulesSDConfPath,
+		StateFile:          cfg.StateFile,
+		LockDir:            cfg.LockDir,
+		RunModule:          cfg.RunModule,
+		MinUpdateEvery:     cfg.MinUpdateEvery,
+		AdminSocket:        cfg.AdminSocket,
+		DiscoveryProviders: cfg.DiscoveryProviders,
+		ModuleRegistry:     cfg.ModuleRegistry,
+		Out:                os.Stdout,
+		reloadCh:           make(chan struct{}, 1),
+		loggers:            cfg.Loggers,
+		outLock:            &sync.Mutex{},
+	}
+	if p.ModuleRegistry == nil {
+		p.ModuleRegistry = module.DefaultRegistry
+	}
+	if p.loggers == nil {
+		p.loggers = logger.Default()
+	}
+
+	p.loggers.SetPrefix(p.Name)
+	p.Logger = p.loggers.New("main", "main")
+	if cfg.LogLevel != "" {
+		if severity, err := logger.ParseSeverity(cfg.LogLevel); err != nil {
+			p.Warning(err)
+		} else {
+			p.loggers.SetSeverity(severity)
+		}
+	}
 	p.api = netdataapi.New(p.Out)
 
 	return p
 }
 
-// Run
+// Run starts the plugin. It returns after the graceful shutdown on SIGINT or SIGTERM.
 func (p *Plugin) Run() {
-	go p.signalHandling()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go p.signalHandling(cancel)
 	go p.keepAlive()
-	serve(p)
+	serve(ctx, p)
 }
 
-func serve(p *Plugin) {
+func serve(ctx context.Context, p *Plugin) {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGHUP)
-	var wg sync.WaitGroup
-
-	for {
-		ctx, cancel := context.WithCancel(context.Background())
+	defer signal.Stop(ch)
 
-		wg.Add(1)
-		go func() { defer wg.Done(); p.run(ctx) }()
+	done := p.start(ctx)
 
-		sig := <-ch
-		p.Infof("received %s signal (%d), stopping running instance", sig, sig)
-		cancel()
-		wg.Wait()
-		time.Sleep(time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			p.Info("stopping running instance")
+			<-done
+			return
+		case sig := <-ch:
+			select {
+			case <-done:
+				// the instance has exited (plugin or all modules are disabled), the configuration may be changed.
+				p.Infof("received %s signal (%d), starting instance", sig, sig)
+				done = p.start(ctx)
+			default:
+				p.Infof("received %s signal (%d), reloading configuration", sig, sig)
+				p.triggerReload()
+			}
+		}
 	}
 }
 
+func (p *Plugin) start(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() { defer close(done); p.run(ctx) }()
+	return done
+}
+
 func (p *Plugin) run(ctx context.Context) {
 	p.Info("instance is started")
 	defer func() { p.Info("instance is stopped") }()
@@ -127,7 +185,7 @@ func (p *Plugin) run(ctx context.Context) {
 		return
 	}
 
-	discCfg := p.buildDiscoveryConf(enabled)
+	discCfg := p.buildDiscoveryConf(cfg, enabled)
 
 	discoverer, err := discovery.NewManager(discCfg)
 	if err != nil {
@@ -139,20 +197,30 @@ func (p *Plugin) run(ctx context.Context) {
 	}
 
 	runner := run.NewManager()
+	runner.Logger = p.loggers.New("run", "manager")
 
 	builder := build.NewManager()
+	builder.Logger = p.loggers.New("build", "manager")
+	builder.Loggers = p.loggers
 	builder.Runner = runner
 	builder.PluginName = p.Name
-	builder.Out = p.Out
+	out := &countingWriter{w: p.Out}
+	builder.Out = out
+	builder.OutLock = p.outLock
 	builder.Modules = enabled
 
 	if p.LockDir != "" {
 		builder.Registry = registry.NewFileLockRegistry(p.LockDir)
 	}
 
+	// state saver has its own context, it is stopped (and makes the final flush) after the builder is stopped.
+	saverCtx, saverCancel := context.WithCancel(context.Background())
+	defer saverCancel()
+
 	var saver *state.Manager
 	if !isTerminal && p.StateFile != "" {
 		saver = state.NewManager(p.StateFile)
+		saver.Logger = p.loggers.New("state save", "manager")
 		builder.CurState = saver
 		if st, err := state.Load(p.StateFile); err != nil {
 			p.Warningf("couldn't load state file: %v", err)
@@ -170,32 +238,53 @@ func (p *Plugin) run(ctx context.Context) {
 	wg.Add(1)
 	go func() { defer wg.Done(); builder.Run(ctx, in) }()
 
-	wg.Add(1)
-	go func() { defer wg.Done(); discoverer.Run(ctx, in) }()
+	if cfg.SelfMonitoring {
+		if job := p.newSelfMonitorJob(builder, out); job.AutoDetection() {
+			runner.Start(job)
+		}
+	}
 
-	if saver != nil {
+	if p.AdminSocket != "" {
 		wg.Add(1)
-		go func() { defer wg.Done(); saver.Run(ctx) }()
+		go func() { defer wg.Done(); p.serveAdmin(ctx, builder, runner) }()
+	}
+
+	var saverWg sync.WaitGroup
+	if saver != nil {
+		saverWg.Add(1)
+		go func() { defer saverWg.Done(); saver.Run(saverCtx) }()
 	}
 
+	p.runDiscovery(ctx, builder, discoverer, in)
+
+	// shutdown order: the builder stops started jobs and releases their registrations,
+	// the runner stops the rest of jobs (if any), the state saver makes the final flush.
 	wg.Wait()
 	<-ctx.Done()
 	runner.Cleanup()
+	saverCancel()
+	saverWg.Wait()
 }
 
-func (p *Plugin) signalHandling() {
+func (p *Plugin) signalHandling(shutdown context.CancelFunc) {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM, syscall.SIGPIPE)
 
 	sig := <-ch
 	p.Infof("received %s signal (%d). Terminating...", sig, sig)
 
-	switch sig {
-	case syscall.SIGPIPE:
+	if sig == syscall.SIGPIPE {
 		os.Exit(1)
-	default:
-		os.Exit(0)
 	}
+	shutdown()
+
+	select {
+	case sig = <-ch:
+		p.Warningf("received %s signal (%d) during shutdown, exiting immediately", sig, sig)
+	case <-time.After(shutdownTimeout):
+		p.Warningf("graceful shutdown is not finished in %s, exiting", shutdownTimeout)
+	}
+	os.Exit(0)
 }
 
 func (p *Plugin) keepAlive() {
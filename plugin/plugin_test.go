@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,6 +60,88 @@ func TestPlugin_Run(t *testing.T) {
 	assert.True(t, buf.String() != "")
 }
 
+func TestPlugin_run_MultipleInstances(t *testing.T) {
+	type instance struct {
+		plugin  *Plugin
+		buf     bytes.Buffer
+		loggers *logger.Factory
+		stats   map[string]int
+	}
+	var mux sync.Mutex
+	newInstance := func(name, logLevel string, modules ...string) *instance {
+		in := &instance{loggers: logger.NewFactory(name), stats: make(map[string]int)}
+		in.plugin = New(Config{
+			Name:           name,
+			ModuleRegistry: prepareRegistry(&mux, in.stats, modules...),
+			Loggers:        in.loggers,
+			LogLevel:       logLevel,
+		})
+		in.plugin.Out = &in.buf
+		return in
+	}
+	defaultSeverity := logger.Default().Severity()
+
+	in1 := newInstance("plugin1", "debug", "module1")
+	in2 := newInstance("plugin2", "error", "module2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, in := range []*instance{in1, in2} {
+		p := in.plugin
+		wg.Add(1)
+		go func() { defer wg.Done(); p.run(ctx) }()
+	}
+
+	time.Sleep(time.Second * 2)
+	cancel()
+	wg.Wait()
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 1, in1.stats["module1_init"])
+	assert.Equal(t, 0, in1.stats["module2_init"])
+	assert.Equal(t, 1, in2.stats["module2_init"])
+	assert.Equal(t, 0, in2.stats["module1_init"])
+
+	assert.Contains(t, in1.buf.String(), "'plugin1' 'module1'")
+	assert.NotContains(t, in1.buf.String(), "'plugin2'")
+	assert.Contains(t, in2.buf.String(), "'plugin2' 'module2'")
+	assert.NotContains(t, in2.buf.String(), "'plugin1'")
+
+	assert.Equal(t, logger.DEBUG, in1.loggers.Severity())
+	assert.Equal(t, logger.ERROR, in2.loggers.Severity())
+	assert.Equal(t, defaultSeverity, logger.Default().Severity())
+}
+
+func TestServe_Shutdown(t *testing.T) {
+	p := New(Config{})
+
+	var buf bytes.Buffer
+	p.Out = &buf
+
+	var mux sync.Mutex
+	stats := make(map[string]int)
+	p.ModuleRegistry = prepareRegistry(&mux, stats, "module1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { defer close(done); serve(ctx, p) }()
+
+	time.Sleep(time.Second * 2)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("serve didn't return after the context cancellation")
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equalf(t, 1, stats["module1_cleanup"], "module1 cleanup")
+	assert.Contains(t, buf.String(), "obsolete")
+}
+
 func prepareRegistry(mux *sync.Mutex, stats map[string]int, names ...string) module.Registry {
 	reg := module.Registry{}
 	for _, name := range names {
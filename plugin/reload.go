@@ -0,0 +1,210 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/build"
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/file"
+	"github.com/netdata/go-orchestrator/module"
+)
+
+// triggerReload requests the configuration reload, it is no-op if a reload is already pending.
+func (p *Plugin) triggerReload() {
+	select {
+	case p.reloadCh <- struct{}{}:
+	default:
+	}
+}
+
+// providerResyncTimeout is the time the restarted providers have to re-send groups for their sources
+// after the new discovery manager first send, empty groups are sent for the sources that are not re-sent in time.
+// It should be longer than the discovery manager groups batching interval (2 seconds).
+var providerResyncTimeout = time.Minute
+
+// runDiscovery runs the discovery and restarts it on every configuration reload. It blocks until ctx is done.
+//
+// Reload is incremental: the builder keeps per source config groups and starts/stops only changed jobs,
+// so the new discovery instance re-sends groups for all the sources it knows about, and empty groups
+// are sent for the sources the new configuration doesn't cover anymore.
+// The providers sources are gone if the restarted provider doesn't re-send them (see providerResyncTimeout).
+// If the new configuration can't be loaded the current one keeps running.
+func (p *Plugin) runDiscovery(ctx context.Context, builder *build.Manager, mgr *discovery.Manager,
+	in chan<- []*confgroup.Group) {
+	sources := make(map[string]string)
+	stop := startDiscovery(ctx, mgr, sources, nil, in)
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			return
+		case <-p.reloadCh:
+		}
+
+		newCfg, enabled, newMgr, ok := p.reloadDiscoveryConf()
+		if !ok {
+			p.Warning("configuration reload failed, keeping the current configuration")
+			continue
+		}
+
+		stop()
+		builder.SetModules(enabled)
+
+		var removed []*confgroup.Group
+		resync := make(map[string]bool)
+		for source, provider := range sources {
+			if !isSourceCovered(newCfg, source, provider) {
+				removed = append(removed, &confgroup.Group{Source: source})
+				delete(sources, source)
+			} else if _, ok := newCfg.Providers[provider]; ok {
+				resync[source] = true
+			}
+		}
+		if len(removed) > 0 {
+			p.Infof("configuration reload: %d source(s) are not covered by the new configuration, removing", len(removed))
+			select {
+			case <-ctx.Done():
+				return
+			case in <- removed:
+			}
+		}
+
+		stop = func() {}
+		if newMgr != nil {
+			stop = startDiscovery(ctx, newMgr, sources, resync, in)
+		}
+		p.Infof("configuration reloaded, enabled modules: %d", len(enabled))
+	}
+}
+
+// reloadDiscoveryConf loads the plugin configuration and builds the discovery config.
+// The discovery manager is nil if there are no enabled modules.
+func (p *Plugin) reloadDiscoveryConf() (discovery.Config, module.Registry, *discovery.Manager, bool) {
+	cfg, err := p.readPluginConfig()
+	if err != nil {
+		p.Error(err)
+		return discovery.Config{}, nil, nil, false
+	}
+	p.Infof("using config: %s", cfg)
+
+	if !cfg.Enabled {
+		p.Info("plugin is disabled in the configuration file, stopping all jobs")
+		return discovery.Config{}, module.Registry{}, nil, true
+	}
+
+	enabled := p.loadEnabledModules(cfg)
+	if len(enabled) == 0 {
+		p.Info("no modules to run, stopping all jobs")
+		return discovery.Config{}, enabled, nil, true
+	}
+
+	discCfg := p.buildDiscoveryConf(cfg, enabled)
+	mgr, err := discovery.NewManager(discCfg)
+	if err != nil {
+		p.Error(err)
+		return discovery.Config{}, nil, nil, false
+	}
+	return discCfg, enabled, mgr, true
+}
+
+// startDiscovery runs the discovery manager and forwards its groups to the builder,
+// keeping track of non-empty group sources and their providers. Empty groups are sent for the resync sources
+// (the restarted providers sources) that are not re-sent within providerResyncTimeout after the manager first send.
+// The returned function stops the discovery and waits for it.
+func startDiscovery(ctx context.Context, mgr *discovery.Manager, sources map[string]string, resync map[string]bool,
+	in chan<- []*confgroup.Group) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan []*confgroup.Group)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() { defer wg.Done(); mgr.Run(ctx, out) }()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		var resyncTimeout <-chan time.Time
+		var tm *time.Timer
+		defer func() {
+			if tm != nil {
+				tm.Stop()
+			}
+		}()
+
+		for {
+			var groups []*confgroup.Group
+			select {
+			case <-ctx.Done():
+				return
+			case groups = <-out:
+			case <-resyncTimeout:
+				for source := range resync {
+					groups = append(groups, &confgroup.Group{Source: source})
+				}
+				resync = nil
+			}
+			if len(groups) == 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case in <- groups:
+			}
+			for _, group := range groups {
+				if group == nil {
+					continue
+				}
+				delete(resync, group.Source)
+				if len(group.Configs) == 0 {
+					delete(sources, group.Source)
+				} else {
+					sources[group.Source] = group.Configs[0].Provider()
+				}
+			}
+			if tm == nil && len(resync) > 0 {
+				// the manager sends the first groups right away and batches the rest,
+				// the providers get the time from the first send.
+				tm = time.NewTimer(providerResyncTimeout)
+				resyncTimeout = tm.C
+			}
+		}
+	}()
+
+	return func() { cancel(); wg.Wait() }
+}
+
+// isSourceCovered returns whether the discovery with the config will (re-)send a group for the source.
+// Sources of the providers are covered as long as the provider is in the config.
+func isSourceCovered(cfg discovery.Config, source, provider string) bool {
+	if _, ok := cfg.Providers[provider]; ok && provider != "" {
+		return true
+	}
+	for _, name := range cfg.Dummy.Names {
+		if source == name {
+			return true
+		}
+	}
+	for _, pattern := range cfg.File.Read {
+		if ok, _ := file.Match(pattern, source); ok && isRegularFile(source) && !file.IsExcluded(cfg.File.Exclude, source) {
+			return true
+		}
+	}
+	for _, pattern := range cfg.File.Watch {
+		if ok, _ := file.Match(pattern, source); ok && isRegularFile(source) && !file.IsExcluded(cfg.File.Exclude, source) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRegularFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/confgroup"
+	"github.com/netdata/go-orchestrator/job/discovery"
+	"github.com/netdata/go-orchestrator/job/discovery/dummy"
+	"github.com/netdata/go-orchestrator/job/discovery/file"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlugin_runReload(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-reload")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	writeConf := func(name, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	writeConf("test.conf", "modules:\n  module3: yes\n")
+	writeConf("module1.conf", "jobs:\n  - name: job1\n")
+	writeConf("module2.conf", "jobs:\n  - name: job1\n")
+
+	p := New(Config{Name: "test", ConfDir: []string{dir}, ModulesConfDir: []string{dir}})
+	var buf bytes.Buffer
+	p.Out = &buf
+
+	var mux sync.Mutex
+	stats := make(map[string]int)
+	p.ModuleRegistry = prepareRegistry(&mux, stats, "module1", "module2", "module3")
+	getStats := func() map[string]int {
+		mux.Lock()
+		defer mux.Unlock()
+		cp := make(map[string]int)
+		for k, v := range stats {
+			if k == "module1_init" || k == "module2_init" || k == "module3_init" ||
+				k == "module1_cleanup" || k == "module2_cleanup" || k == "module3_cleanup" {
+				cp[k] = v
+			}
+		}
+		return cp
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); p.run(ctx) }()
+	defer func() { cancel(); wg.Wait() }()
+
+	time.Sleep(time.Second * 3)
+	assert.Equal(t, map[string]int{"module1_init": 1, "module2_init": 1, "module3_init": 1}, getStats())
+
+	// module1: unchanged, module2: changed, module3: disabled.
+	writeConf("test.conf", "modules:\n  module3: no\n")
+	writeConf("module2.conf", "jobs:\n  - name: job1\n    update_every: 2\n")
+	p.triggerReload()
+
+	time.Sleep(time.Second * 3)
+	assert.Equal(t, map[string]int{
+		"module1_init":    1,
+		"module2_init":    2,
+		"module2_cleanup": 1,
+		"module3_init":    1,
+		"module3_cleanup": 1,
+	}, getStats())
+
+	// invalid plugin config: the current configuration keeps running.
+	writeConf("test.conf", "modules: [\n")
+	p.triggerReload()
+
+	time.Sleep(time.Second)
+	assert.Equal(t, map[string]int{
+		"module1_init":    1,
+		"module2_init":    2,
+		"module2_cleanup": 1,
+		"module3_init":    1,
+		"module3_cleanup": 1,
+	}, getStats())
+
+	// module config removed: the module job is stopped.
+	writeConf("test.conf", "modules:\n  module3: no\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "module1.conf")))
+	p.triggerReload()
+
+	time.Sleep(time.Second * 3)
+	assert.Equal(t, map[string]int{
+		"module1_init":    2,
+		"module1_cleanup": 1,
+		"module2_init":    2,
+		"module2_cleanup": 1,
+		"module3_init":    1,
+		"module3_cleanup": 1,
+	}, getStats())
+}
+
+func TestPlugin_runReload_Providers(t *testing.T) {
+	defer func(timeout time.Duration) { providerResyncTimeout = timeout }(providerResyncTimeout)
+	// longer than the discovery manager batching interval.
+	providerResyncTimeout = time.Second * 3
+
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-reload")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, name := range []string{"module1.conf", "module2.conf"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("jobs: []\n"), 0644))
+	}
+
+	// the first instance discovers 'job1' and 'job2', the instances after the reload only 'job1'.
+	var instances int
+	provider := func(cfg discovery.ProviderConfig) (discovery.Discoverer, error) {
+		instances++
+		groups := []*confgroup.Group{
+			{Source: "custom:job1", Configs: []confgroup.Config{{"name": "job1", "module": "module1"}}},
+			{Source: "custom:job2", Configs: []confgroup.Config{{"name": "job2", "module": "module2"}}},
+		}
+		if instances > 1 {
+			groups = groups[:1]
+		}
+		for _, group := range groups {
+			for _, c := range group.Configs {
+				// the provider own name differs from the registered one.
+				c.SetProvider("other")
+				def, _ := cfg.Registry.Lookup(c.Module())
+				c.Apply(def)
+			}
+		}
+		return &providerDiscoverer{groups: groups}, nil
+	}
+
+	p := New(Config{
+		Name:               "test",
+		ModulesConfDir:     []string{dir},
+		DiscoveryProviders: discovery.Providers{"custom": provider},
+	})
+	var buf bytes.Buffer
+	p.Out = &buf
+
+	var mux sync.Mutex
+	stats := make(map[string]int)
+	p.ModuleRegistry = prepareRegistry(&mux, stats, "module1", "module2")
+	getStats := func() map[string]int {
+		mux.Lock()
+		defer mux.Unlock()
+		cp := make(map[string]int)
+		for _, k := range []string{"module1_init", "module2_init", "module1_cleanup", "module2_cleanup"} {
+			if v, ok := stats[k]; ok {
+				cp[k] = v
+			}
+		}
+		return cp
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { defer wg.Done(); p.run(ctx) }()
+	defer func() { cancel(); wg.Wait() }()
+
+	time.Sleep(time.Second * 3)
+	assert.Equal(t, map[string]int{"module1_init": 1, "module2_init": 1}, getStats())
+
+	// 'job1' is kept running, 'job2' is not re-sent by the restarted provider.
+	p.triggerReload()
+
+	time.Sleep(time.Second * 5)
+	assert.Equal(t, map[string]int{"module1_init": 1, "module2_init": 1, "module2_cleanup": 1}, getStats())
+}
+
+type providerDiscoverer struct {
+	groups []*confgroup.Group
+}
+
+func (d *providerDiscoverer) Run(ctx context.Context, in chan<- []*confgroup.Group) {
+	select {
+	case <-ctx.Done():
+	case in <- d.groups:
+	}
+	<-ctx.Done()
+}
+
+func TestIsSourceCovered(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-reload")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	existing := filepath.Join(dir, "module.conf")
+	require.NoError(t, ioutil.WriteFile(existing, nil, 0644))
+	notExisting := filepath.Join(dir, "not-exist.conf")
+
+	tests := map[string]struct {
+		cfg      discovery.Config
+		source   string
+		provider string
+		want     bool
+	}{
+		"dummy name":                 {cfg: discovery.Config{Dummy: dummy.Config{Names: []string{"module"}}}, source: "module", want: true},
+		"not a dummy name":           {cfg: discovery.Config{Dummy: dummy.Config{Names: []string{"module"}}}, source: "module1"},
+		"read path":                  {cfg: discovery.Config{File: file.Config{Read: []string{existing}}}, source: existing, want: true},
+		"read path doesnt exist":     {cfg: discovery.Config{File: file.Config{Read: []string{notExisting}}}, source: notExisting},
+		"watch pattern":              {cfg: discovery.Config{File: file.Config{Watch: []string{dir + "/*.conf"}}}, source: existing, want: true},
+		"watch pattern doesnt match": {cfg: discovery.Config{File: file.Config{Watch: []string{dir + "/*.yml"}}}, source: existing},
+		"watch file doesnt exist":    {cfg: discovery.Config{File: file.Config{Watch: []string{dir + "/*.conf"}}}, source: notExisting},
+		"recursive watch pattern":    {cfg: discovery.Config{File: file.Config{Watch: []string{dir + "/**/*.conf"}}}, source: existing, want: true},
+		"watch file excluded": {
+			cfg:    discovery.Config{File: file.Config{Watch: []string{dir + "/*.conf"}, Exclude: []string{"module.*"}}},
+			source: existing,
+		},
+		"empty config": {source: existing},
+		"provider": {
+			cfg:      discovery.Config{Providers: discovery.Providers{"docker": nil}},
+			source:   "docker:container1",
+			provider: "docker",
+			want:     true,
+		},
+		"provider not in config": {
+			cfg:      discovery.Config{Providers: discovery.Providers{"docker": nil}},
+			source:   "k8s:pod1",
+			provider: "k8s",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, isSourceCovered(test.cfg, test.source, test.provider))
+		})
+	}
+}
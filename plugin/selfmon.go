@@ -0,0 +1,206 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/netdata/go-orchestrator/job/build"
+	"github.com/netdata/go-orchestrator/module"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+)
+
+const selfMonPriority = 144000
+
+// jobStates are the build.Manager job states, the order is the order of the chart dimensions.
+var jobStates = []string{
+	"success",
+	"retry",
+	"failed",
+	"duplicate_local",
+	"duplicate_global",
+	"registration_error",
+	"build_error",
+	"stopped",
+}
+
+// countingWriter counts bytes written to the plugin output.
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	atomic.AddInt64(&w.count, int64(n))
+	return n, err
+}
+
+func (w *countingWriter) written() int64 {
+	return atomic.LoadInt64(&w.count)
+}
+
+// selfMonitor is the plugin self-monitoring module, it is enabled by 'self_monitoring' in the plugin config.
+// It is run as a regular job, its charts are under the 'netdata' type like the jobs execution time charts.
+type selfMonitor struct {
+	module.Base
+	pluginName string
+	builder    *build.Manager
+	out        *countingWriter
+	loggers    *logger.Factory
+
+	charts    *module.Charts
+	providers map[string]bool
+}
+
+func (p *Plugin) newSelfMonitorJob(builder *build.Manager, out *countingWriter) *module.Job {
+	mon := &selfMonitor{
+		pluginName: p.Name,
+		builder:    builder,
+		out:        out,
+		loggers:    p.loggers,
+		providers:  make(map[string]bool),
+	}
+	return module.NewJob(module.JobConfig{
+		PluginName:   p.Name,
+		Name:         "internal",
+		ModuleName:   "internal",
+		FullName:     p.Name + "_internal",
+		Module:       mon,
+		Out:          out,
+		OutLock:      p.outLock,
+		Loggers:      p.loggers,
+		UpdateEvery:  1,
+		Priority:     selfMonPriority,
+		ChartsTypeID: "netdata",
+	})
+}
+
+func (m *selfMonitor) Init() bool {
+	m.charts = m.newCharts()
+	return true
+}
+
+func (m *selfMonitor) Check() bool { return true }
+
+func (m *selfMonitor) Charts() *module.Charts { return m.charts }
+
+func (m *selfMonitor) Cleanup() {}
+
+func (m *selfMonitor) Collect() map[string]int64 {
+	mx := make(map[string]int64)
+
+	mx["goroutines"] = int64(runtime.NumGoroutine())
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	mx["heap_alloc"] = int64(ms.HeapAlloc)
+	mx["heap_inuse"] = int64(ms.HeapInuse)
+	mx["heap_sys"] = int64(ms.HeapSys)
+	mx["gc_num"] = int64(ms.NumGC)
+	mx["gc_pause_total"] = int64(ms.PauseTotalNs)
+
+	for _, st := range jobStates {
+		mx["jobs_"+st] = 0
+	}
+	for _, st := range m.builder.Statuses() {
+		mx["jobs_"+st.State]++
+	}
+
+	for provider := range m.providers {
+		mx["groups_"+providerID(provider)] = 0
+	}
+	for _, cfgs := range m.builder.Groups() {
+		if len(cfgs) == 0 {
+			continue
+		}
+		provider := cfgs[0].Provider()
+		if provider == "" {
+			provider = "unknown"
+		}
+		m.addProvider(provider)
+		mx["groups_"+providerID(provider)]++
+	}
+
+	mx["output_bytes"] = m.out.written()
+	mx["logger_dropped"] = m.loggers.Dropped()
+
+	return mx
+}
+
+func (m *selfMonitor) addProvider(provider string) {
+	if m.providers[provider] {
+		return
+	}
+	m.providers[provider] = true
+
+	chart := m.charts.Get(m.chartID("discovery_groups"))
+	if chart == nil {
+		return
+	}
+	if err := chart.AddDim(&module.Dim{ID: "groups_" + providerID(provider), Name: provider}); err != nil {
+		m.Warning(err)
+		return
+	}
+	chart.MarkNotCreated()
+}
+
+func (m *selfMonitor) chartID(id string) string {
+	return m.pluginName + "_" + id
+}
+
+func (m *selfMonitor) newCharts() *module.Charts {
+	newChart := func(id, title, units string, dims ...*module.Dim) *module.Chart {
+		return &module.Chart{
+			ID:    m.chartID(id),
+			Title: fmt.Sprintf("%s %s", m.pluginName, title),
+			Units: units,
+			Fam:   m.pluginName,
+			Ctx:   "netdata.go_plugin_" + id,
+			Dims:  dims,
+		}
+	}
+
+	var jobDims module.Dims
+	for _, st := range jobStates {
+		jobDims = append(jobDims, &module.Dim{ID: "jobs_" + st, Name: st})
+	}
+
+	charts := module.Charts{
+		newChart("goroutines", "Goroutines", "goroutines",
+			&module.Dim{ID: "goroutines"},
+		),
+		newChart("heap", "Heap Memory", "bytes",
+			&module.Dim{ID: "heap_alloc", Name: "alloc"},
+			&module.Dim{ID: "heap_inuse", Name: "inuse"},
+			&module.Dim{ID: "heap_sys", Name: "sys"},
+		),
+		newChart("gc", "Garbage Collections", "collections/s",
+			&module.Dim{ID: "gc_num", Name: "collections", Algo: module.Incremental},
+		),
+		newChart("gc_pause", "Garbage Collection Pause Time", "ms/s",
+			&module.Dim{ID: "gc_pause_total", Name: "pause", Algo: module.Incremental, Div: 1000000},
+		),
+		newChart("jobs", "Jobs By Build State", "jobs", jobDims...),
+		newChart("discovery_groups", "Discovered Config Groups By Provider", "groups"),
+		newChart("output", "Output", "KiB/s",
+			&module.Dim{ID: "output_bytes", Name: "written", Algo: module.Incremental, Div: 1024},
+		),
+		newChart("logger_dropped", "Logger Messages Dropped By Rate Limiting", "messages/s",
+			&module.Dim{ID: "logger_dropped", Name: "dropped", Algo: module.Incremental},
+		),
+	}
+	for i, chart := range charts {
+		chart.Priority = selfMonPriority + i
+	}
+	charts[4].Type = module.Stacked
+	charts[5].Type = module.Stacked
+	return &charts
+}
+
+// providerID makes a provider name usable as a dimension ID ("file watcher" => "file_watcher").
+func providerID(provider string) string {
+	return strings.Join(strings.Fields(provider), "_")
+}
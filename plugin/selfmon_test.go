@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/netdata/go-orchestrator/job/build"
+	"github.com/netdata/go-orchestrator/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountingWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	w := &countingWriter{w: &buf}
+
+	_, _ = w.Write([]byte("hello"))
+	_, _ = w.Write([]byte(" world"))
+
+	assert.Equal(t, int64(11), w.written())
+	assert.Equal(t, "hello world", buf.String())
+}
+
+func TestSelfMonitor_Collect(t *testing.T) {
+	var buf bytes.Buffer
+	out := &countingWriter{w: &buf}
+	_, _ = out.Write([]byte("CHART\n"))
+
+	mon := &selfMonitor{
+		pluginName: "test",
+		builder:    build.NewManager(),
+		out:        out,
+		providers:  make(map[string]bool),
+	}
+	require.True(t, mon.Init())
+	require.True(t, mon.Check())
+
+	mx := mon.Collect()
+
+	for _, chart := range *mon.Charts() {
+		for _, dim := range chart.Dims {
+			assert.Containsf(t, mx, dim.ID, "chart '%s' dim '%s'", chart.ID, dim.ID)
+		}
+	}
+	assert.True(t, mx["goroutines"] > 0)
+	assert.True(t, mx["heap_alloc"] > 0)
+	assert.Equal(t, int64(6), mx["output_bytes"])
+	assert.Equal(t, int64(0), mx["jobs_success"])
+
+	mon.addProvider("file watcher")
+	chart := mon.Charts().Get("test_discovery_groups")
+	require.NotNil(t, chart)
+	assert.True(t, chart.HasDim("groups_file_watcher"))
+}
+
+func TestNewSelfMonitorJob(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(Config{Name: "test", Loggers: logger.NewFactory("test")})
+	job := p.newSelfMonitorJob(build.NewManager(), &countingWriter{w: &buf})
+
+	require.True(t, job.AutoDetection())
+	assert.Equal(t, "test_internal", job.FullName())
+}
+
+func TestPlugin_run_SelfMonitoring(t *testing.T) {
+	tests := map[string]struct {
+		config  string
+		enabled bool
+	}{
+		"disabled by default": {},
+		"disabled in the config": {
+			config: "self_monitoring: no",
+		},
+		"enabled in the config": {
+			config:  "self_monitoring: yes",
+			enabled: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir(os.TempDir(), "netdata-go-test-plugin-selfmon")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			if test.config != "" {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "test.conf"), []byte(test.config), 0644))
+			}
+
+			var mux sync.Mutex
+			p := New(Config{
+				Name:           "test",
+				ConfDir:        []string{dir},
+				ModuleRegistry: prepareRegistry(&mux, make(map[string]int), "module1"),
+				Loggers:        logger.NewFactory("test"),
+			})
+			var buf bytes.Buffer
+			p.Out = &buf
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() { defer close(done); p.run(ctx) }()
+			time.Sleep(time.Second * 2)
+			cancel()
+			<-done
+
+			if test.enabled {
+				assert.Contains(t, buf.String(), "'netdata.test_goroutines'")
+			} else {
+				assert.NotContains(t, buf.String(), "'netdata.test_goroutines'")
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/netdata/go-orchestrator/job/confgroup"
 	"github.com/netdata/go-orchestrator/job/discovery"
@@ -28,6 +31,23 @@ type config struct {
 	DefaultRun bool            `yaml:"default_run"`
 	MaxProcs   int             `yaml:"max_procs"`
 	Modules    map[string]bool `yaml:"modules"`
+	// Defaults are the all modules jobs defaults.
+	Defaults confgroup.Default `yaml:"defaults"`
+	// ModuleDefaults are the per module jobs defaults, they take precedence over Defaults.
+	ModuleDefaults map[string]confgroup.Default `yaml:"module_defaults"`
+	// FileWatcher is the modules SD config files watcher configuration.
+	FileWatcher fileWatcherConfig `yaml:"file_watcher"`
+	// SelfMonitoring enables the plugin internal charts (the 'internal' job), disabled by default.
+	SelfMonitoring bool `yaml:"self_monitoring"`
+}
+
+type fileWatcherConfig struct {
+	// Polling forces the polling mode, by default the watcher falls back to polling if inotify is not available.
+	Polling bool `yaml:"polling"`
+	// PollEvery is the polling interval in seconds.
+	PollEvery int `yaml:"poll_every"`
+	// Exclude are the exclusion patterns of the modules config files and the SD config files.
+	Exclude []string `yaml:"exclude"`
 }
 
 func (c config) String() string {
@@ -36,11 +56,21 @@ func (c config) String() string {
 }
 
 func (p *Plugin) loadPluginConfig() config {
+	cfg, err := p.readPluginConfig()
+	if err != nil {
+		p.Warningf("%v, will use defaults", err)
+		return defaultConfig()
+	}
+	return cfg
+}
+
+// readPluginConfig is like loadPluginConfig, but it returns an error if the config file exists and can't be loaded.
+func (p *Plugin) readPluginConfig() (config, error) {
 	p.Info("loading config file")
 
 	if len(p.ConfDir) == 0 {
 		p.Info("config dir not provided, will use defaults")
-		return defaultConfig()
+		return defaultConfig(), nil
 	}
 
 	cfgPath := p.Name + ".conf"
@@ -49,34 +79,34 @@ func (p *Plugin) loadPluginConfig() config {
 	path, err := p.ConfDir.Find(cfgPath)
 	if err != nil || path == "" {
 		p.Warning("couldn't find config, will use defaults")
-		return defaultConfig()
+		return defaultConfig(), nil
 	}
 	p.Infof("found '%s", path)
 
 	cfg := defaultConfig()
 	if err := loadYAML(&cfg, path); err != nil {
-		p.Warningf("couldn't load config '%s': %v, will use defaults", path, err)
-		return defaultConfig()
+		return cfg, fmt.Errorf("couldn't load config '%s': %v", path, err)
 	}
 	p.Info("config successfully loaded")
-	return cfg
+	return cfg, nil
 }
 
 func (p *Plugin) loadEnabledModules(cfg config) module.Registry {
 	p.Info("loading modules")
 
-	all := p.RunModule == "all" || p.RunModule == ""
+	sel := parseModuleSelector(p.RunModule)
 	enabled := module.Registry{}
 
 	for name, creator := range p.ModuleRegistry {
-		if !all && p.RunModule != name {
+		explicit, ok := sel.match(name)
+		if !ok {
 			continue
 		}
-		if all && creator.Disabled && !cfg.isExplicitlyEnabled(name) {
+		if !explicit && creator.Disabled && !cfg.isExplicitlyEnabled(name) {
 			p.Infof("'%s' module disabled by default, should be explicitly enabled in the config", name)
 			continue
 		}
-		if all && !cfg.isImplicitlyEnabled(name) {
+		if !explicit && !cfg.isImplicitlyEnabled(name) {
 			p.Infof("'%s' module disabled in the config file", name)
 			continue
 		}
@@ -86,18 +116,10 @@ func (p *Plugin) loadEnabledModules(cfg config) module.Registry {
 	return enabled
 }
 
-func (p *Plugin) buildDiscoveryConf(enabled module.Registry) discovery.Config {
+func (p *Plugin) buildDiscoveryConf(cfg config, enabled module.Registry) discovery.Config {
 	p.Info("building discovery config")
 
-	reg := confgroup.Registry{}
-	for name, creator := range enabled {
-		reg.Register(name, confgroup.Default{
-			MinUpdateEvery:     p.MinUpdateEvery,
-			UpdateEvery:        creator.UpdateEvery,
-			AutoDetectionRetry: creator.AutoDetectionRetry,
-			Priority:           creator.Priority,
-		})
-	}
+	reg := p.buildConfGroupRegistry(cfg, enabled)
 
 	var readPaths, dummyPaths []string
 
@@ -107,8 +129,11 @@ func (p *Plugin) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 			dummyPaths = append(dummyPaths, name)
 		}
 		return discovery.Config{
-			Registry: reg,
-			Dummy:    dummy.Config{Names: dummyPaths}}
+			Registry:  reg,
+			Dummy:     dummy.Config{Names: dummyPaths},
+			Providers: p.DiscoveryProviders,
+			Loggers:   p.loggers,
+		}
 	}
 
 	for name := range enabled {
@@ -129,13 +154,95 @@ func (p *Plugin) buildDiscoveryConf(enabled module.Registry) discovery.Config {
 	return discovery.Config{
 		Registry: reg,
 		File: file.Config{
-			Read:  readPaths,
-			Watch: p.ModulesSDConfPath,
+			Read:      readPaths,
+			Watch:     p.ModulesSDConfPath,
+			Polling:   cfg.FileWatcher.Polling,
+			PollEvery: time.Duration(cfg.FileWatcher.PollEvery) * time.Second,
+			Exclude:   cfg.FileWatcher.Exclude,
 		},
 		Dummy: dummy.Config{
 			Names: dummyPaths,
 		},
+		Providers: p.DiscoveryProviders,
+		Loggers:   p.loggers,
+	}
+}
+
+// buildConfGroupRegistry builds the modules defaults. The precedence (from highest to lowest) is:
+//   - the module configuration file top level (applied by the file discovery).
+//   - the plugin configuration file 'module_defaults' section.
+//   - the plugin configuration file 'defaults' section.
+//   - the module creator defaults.
+//
+// The job config values take precedence over all the defaults, labels are merged by key.
+// Only positive values are applied: zero means not set, a higher precedence level can't set a value back to 0
+// (e.g. 'module_defaults' autodetection_retry: 0 keeps the 'defaults' value).
+// The minimum update_every (the plugin command line argument) is applied at the end.
+func (p *Plugin) buildConfGroupRegistry(cfg config, modules module.Registry) confgroup.Registry {
+	reg := confgroup.Registry{}
+	for name, creator := range modules {
+		def := cfg.ModuleDefaults[name].Merge(cfg.Defaults).Merge(confgroup.Default{
+			UpdateEvery:        creator.UpdateEvery,
+			AutoDetectionRetry: creator.AutoDetectionRetry,
+			Priority:           creator.Priority,
+		})
+		def.MinUpdateEvery = p.MinUpdateEvery
+		reg.Register(name, def)
+	}
+	return reg
+}
+
+// moduleSelector selects modules to run by the comma separated list of names and glob patterns,
+// '!' prefixed items are exclusions. If there are no inclusions (or "all" is included) all modules are selected.
+type moduleSelector struct {
+	all     bool
+	include []string
+	exclude []string
+}
+
+func parseModuleSelector(s string) moduleSelector {
+	var sel moduleSelector
+	for _, item := range strings.Split(s, ",") {
+		switch item = strings.TrimSpace(item); {
+		case item == "":
+		case item == "all":
+			sel.all = true
+		case strings.HasPrefix(item, "!"):
+			sel.exclude = append(sel.exclude, strings.TrimPrefix(item, "!"))
+		default:
+			sel.include = append(sel.include, item)
+		}
+	}
+	if len(sel.include) == 0 {
+		sel.all = true
+	}
+	return sel
+}
+
+// match returns whether the module is selected and whether it is selected explicitly (by the exact name inclusion).
+// Modules selected by a glob pattern or "all" are not explicitly selected, the config file on/off still applies to them.
+func (s moduleSelector) match(name string) (explicit, ok bool) {
+	if matchAny(s.exclude, name) {
+		return false, false
+	}
+	for _, item := range s.include {
+		if item == name {
+			return true, true
+		}
+	}
+	if matchAny(s.include, name) {
+		return false, true
+	}
+	return false, s.all
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
 	}
+	return false
 }
 
 func (c config) isExplicitlyEnabled(moduleName string) bool {
@@ -169,7 +276,7 @@ func (c *config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 	for key, value := range m {
 		switch key {
-		case "enabled", "default_run", "max_procs", "modules":
+		case "enabled", "default_run", "max_procs", "modules", "defaults", "module_defaults", "file_watcher", "self_monitoring":
 			continue
 		}
 		var b bool
@@ -3,6 +3,7 @@ package plugin
 import (
 	"testing"
 
+	"github.com/netdata/go-orchestrator/job/confgroup"
 	"github.com/netdata/go-orchestrator/module"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +27,29 @@ func TestConfig_UnmarshalYAML(t *testing.T) {
 				},
 			},
 		},
+		"valid configuration with defaults": {
+			input: "enabled: yes\ndefaults:\n  update_every: 5\n  labels:\n    env: prod\nmodule_defaults:\n  module1:\n    priority: 100\nmodules:\n  module1: yes",
+			wantCfg: config{
+				Enabled: true,
+				Modules: map[string]bool{
+					"module1": true,
+				},
+				Defaults: confgroup.Default{UpdateEvery: 5, Labels: map[string]string{"env": "prod"}},
+				ModuleDefaults: map[string]confgroup.Default{
+					"module1": {Priority: 100},
+				},
+			},
+		},
+		"valid configuration with file watcher": {
+			input: "enabled: yes\nfile_watcher:\n  polling: yes\n  poll_every: 5\n  exclude: ['*.bak']\nmodules:\n  module1: yes",
+			wantCfg: config{
+				Enabled: true,
+				Modules: map[string]bool{
+					"module1": true,
+				},
+				FileWatcher: fileWatcherConfig{Polling: true, PollEvery: 5, Exclude: []string{"*.bak"}},
+			},
+		},
 		"valid configuration with broken modules section": {
 			input: "enabled: yes\ndefault_run: yes\nmodules:\nmodule1: yes\nmodule2: yes",
 			wantCfg: config{
@@ -192,6 +216,115 @@ func TestPlugin_loadEnabledModules(t *testing.T) {
 			},
 			wantModules: module.Registry{},
 		},
+		"load list, modules disabled in config": {
+			plugin: Plugin{
+				RunModule: "module1, module2",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{Defaults: module.Defaults{Disabled: true}},
+					"module2": module.Creator{},
+					"module3": module.Creator{},
+				},
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{Defaults: module.Defaults{Disabled: true}},
+				"module2": module.Creator{},
+			},
+		},
+		"load glob with exclusion": {
+			plugin: Plugin{
+				RunModule: "mod*,!module2",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{},
+					"module2": module.Creator{},
+					"other":   module.Creator{},
+				},
+			},
+			cfg: config{
+				DefaultRun: true,
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{},
+			},
+		},
+		"load glob, module disabled by default": {
+			plugin: Plugin{
+				RunModule: "*",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{},
+					"module2": module.Creator{Defaults: module.Defaults{Disabled: true}},
+				},
+			},
+			cfg: config{
+				DefaultRun: true,
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{},
+			},
+		},
+		"load glob, module disabled in config": {
+			plugin: Plugin{
+				RunModule: "mod*",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{},
+					"module2": module.Creator{},
+				},
+			},
+			cfg: config{
+				DefaultRun: true,
+				Modules:    map[string]bool{"module2": false},
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{},
+			},
+		},
+		"load glob, module disabled by default but explicitly enabled": {
+			plugin: Plugin{
+				RunModule: "*",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{Defaults: module.Defaults{Disabled: true}},
+				},
+			},
+			cfg: config{
+				Modules: map[string]bool{"module1": true},
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{Defaults: module.Defaults{Disabled: true}},
+			},
+		},
+		"load all with exclusions (default_run=true)": {
+			plugin: Plugin{
+				RunModule: "!module2,!other*",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{},
+					"module2": module.Creator{},
+					"module3": module.Creator{Defaults: module.Defaults{Disabled: true}},
+					"other1":  module.Creator{},
+				},
+			},
+			cfg: config{
+				DefaultRun: true,
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{},
+			},
+		},
+		"load all and specific with exclusion (default_run=false)": {
+			plugin: Plugin{
+				RunModule: "all,module2,!module3",
+				ModuleRegistry: module.Registry{
+					"module1": module.Creator{},
+					"module2": module.Creator{},
+					"module3": module.Creator{},
+				},
+			},
+			cfg: config{
+				Modules: map[string]bool{"module1": true, "module3": true},
+			},
+			wantModules: module.Registry{
+				"module1": module.Creator{},
+				"module2": module.Creator{},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -201,6 +334,60 @@ func TestPlugin_loadEnabledModules(t *testing.T) {
 	}
 }
 
+func TestPlugin_buildConfGroupRegistry(t *testing.T) {
+	tests := map[string]struct {
+		cfg     config
+		wantDef confgroup.Default
+	}{
+		"creator defaults": {
+			wantDef: confgroup.Default{MinUpdateEvery: 2, UpdateEvery: 5, Priority: 100},
+		},
+		"plugin defaults": {
+			cfg: config{
+				Defaults: confgroup.Default{UpdateEvery: 10, AutoDetectionRetry: 60, Labels: map[string]string{"env": "prod"}},
+			},
+			wantDef: confgroup.Default{
+				MinUpdateEvery:     2,
+				UpdateEvery:        10,
+				AutoDetectionRetry: 60,
+				Priority:           100,
+				Labels:             map[string]string{"env": "prod"},
+			},
+		},
+		"plugin module defaults": {
+			cfg: config{
+				Defaults: confgroup.Default{UpdateEvery: 10, AutoDetectionRetry: 60, Labels: map[string]string{"env": "prod", "dc": "eu"}},
+				ModuleDefaults: map[string]confgroup.Default{
+					"module1": {UpdateEvery: 20, Labels: map[string]string{"env": "dev"}},
+					"module2": {UpdateEvery: 30},
+				},
+			},
+			wantDef: confgroup.Default{
+				MinUpdateEvery:     2,
+				UpdateEvery:        20,
+				AutoDetectionRetry: 60,
+				Priority:           100,
+				Labels:             map[string]string{"env": "dev", "dc": "eu"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := Plugin{MinUpdateEvery: 2}
+			modules := module.Registry{
+				"module1": module.Creator{Defaults: module.Defaults{UpdateEvery: 5, Priority: 100}},
+			}
+
+			reg := p.buildConfGroupRegistry(test.cfg, modules)
+
+			def, ok := reg.Lookup("module1")
+			require.True(t, ok)
+			assert.Equal(t, test.wantDef, def)
+		})
+	}
+}
+
 // TODO: tech debt
 func TestPlugin_buildDiscoveryConf(t *testing.T) {
 